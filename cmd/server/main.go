@@ -11,7 +11,10 @@ import (
 	"time"
 
 	"github.com/edson-mazvila/url-shortener/internal/config"
+	"github.com/edson-mazvila/url-shortener/internal/geoip"
 	"github.com/edson-mazvila/url-shortener/internal/handler"
+	"github.com/edson-mazvila/url-shortener/internal/migration"
+	"github.com/edson-mazvila/url-shortener/internal/pages"
 	"github.com/edson-mazvila/url-shortener/internal/repository"
 	"github.com/edson-mazvila/url-shortener/internal/service"
 	"github.com/edson-mazvila/url-shortener/internal/storage"
@@ -31,27 +34,76 @@ func main() {
 
 	logger := setupLogger(cfg.Logging)
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(&cfg.Database, logger, os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	logger.Info("starting url shortener service",
 		slog.String("version", "1.0.0"),
 		slog.String("go_version", "1.25"),
 	)
 
+	codeGenerator, err := service.NewCodeGenerator(&cfg.URL)
+	if err != nil {
+		logger.Error("failed to create code generator", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	db, err := storage.NewPostgresDB(ctx, &cfg.Database, logger)
+	st, err := setupStorage(ctx, &cfg.Database, logger)
 	if err != nil {
 		logger.Error("failed to connect to database", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
-	defer db.Close()
+	defer st.close()
 
-	urlRepo := repository.NewURLRepository(db.Pool(), logger)
-	urlService := service.NewURLService(urlRepo, &cfg.URL, logger)
-	urlHandler := handler.NewURLHandler(urlService, logger)
-	healthHandler := handler.NewHealthHandler(db, logger)
+	var cacheStats handler.CacheStatsProvider
+	if cfg.Cache.Enabled {
+		cached := repository.NewCachedURLRepository(st.urls, &cfg.Cache, logger)
+		st.urls = cached
+		cacheStats = cached
+	}
 
-	router := handler.NewRouter(urlHandler, healthHandler, logger)
+	clickAggregator := service.NewClickAggregator(st.urls, &cfg.Analytics, logger)
+	clickAggregator.Start()
+
+	clickRecorder := service.NewClickRecorder(st.clicks, &cfg.Analytics, logger)
+	clickRecorder.Start()
+
+	var geo *geoip.Resolver
+	if cfg.GeoIP.DatabasePath != "" {
+		geo, err = geoip.Open(cfg.GeoIP.DatabasePath, logger)
+		if err != nil {
+			logger.Warn("geo targeting disabled", slog.String("error", err.Error()))
+		}
+		defer geo.Close()
+	}
+
+	urlService := service.NewURLService(st.urls, st.utmTemplates, codeGenerator, clickAggregator, clickRecorder, geo, &cfg.URL, logger)
+	statsService := service.NewStatsService(st.urls, st.clicks, logger)
+	idempotencyService := service.NewIdempotencyService(st.idempotency, &cfg.URL, logger)
+	scheduleService := service.NewScheduleService(st.schedules, urlService, logger)
+	pageRenderer, err := pages.New(cfg.Pages.TemplateDir)
+	if err != nil {
+		logger.Error("failed to load page templates", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	urlHandler := handler.NewURLHandler(urlService, statsService, idempotencyService, pageRenderer, logger)
+	healthHandler := handler.NewHealthHandler(st.health, logger)
+	utmTemplateHandler := handler.NewUTMTemplateHandler(service.NewUTMTemplateService(st.utmTemplates, logger), logger)
+	scheduleHandler := handler.NewScheduleHandler(scheduleService, logger)
+	apiKeyHandler := handler.NewAPIKeyHandler(service.NewAPIKeyService(st.apiKeys, &cfg.Auth, logger), logger)
+
+	adminHandler := handler.NewAdminHandler(cacheStats, urlService, logger)
+
+	router := handler.NewRouter(urlHandler, healthHandler, utmTemplateHandler, scheduleHandler, apiKeyHandler, adminHandler, logger)
 
 	server := &http.Server{
 		Addr:         fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port),
@@ -73,7 +125,8 @@ func main() {
 		}
 	}()
 
-	go startCleanupWorker(context.Background(), urlService, logger)
+	go startCleanupWorker(context.Background(), urlService, idempotencyService, logger)
+	go startScheduler(context.Background(), scheduleService, cfg.URL.SchedulerInterval, logger)
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -86,8 +139,24 @@ func main() {
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer shutdownCancel()
 
-	if err := server.Shutdown(shutdownCtx); err != nil {
-		logger.Error("server shutdown failed", slog.String("error", err.Error()))
+	shutdownErr := server.Shutdown(shutdownCtx)
+	if shutdownErr != nil {
+		logger.Error("server shutdown failed", slog.String("error", shutdownErr.Error()))
+	}
+
+	// Drain buffered clicks with a fresh deadline so a slow HTTP shutdown cannot lose them.
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer drainCancel()
+
+	if err := clickAggregator.Close(drainCtx); err != nil {
+		logger.Error("failed to drain click aggregator", slog.String("error", err.Error()))
+	}
+
+	if err := clickRecorder.Close(drainCtx); err != nil {
+		logger.Error("failed to drain click recorder", slog.String("error", err.Error()))
+	}
+
+	if shutdownErr != nil {
 		os.Exit(1)
 	}
 
@@ -123,7 +192,78 @@ func setupLogger(cfg config.LoggingConfig) *slog.Logger {
 	return slog.New(handler)
 }
 
-func startCleanupWorker(ctx context.Context, urlService *service.URLService, logger *slog.Logger) {
+// stores groups the storage backends selected by the database driver.
+type stores struct {
+	urls         repository.URLStore
+	clicks       repository.ClickStore
+	idempotency  repository.IdempotencyStore
+	utmTemplates repository.UTMTemplateStore
+	schedules    repository.ScheduleStore
+	apiKeys      repository.APIKeyStore
+	health       handler.HealthChecker
+	close        func()
+}
+
+func setupStorage(ctx context.Context, cfg *config.DatabaseConfig, logger *slog.Logger) (*stores, error) {
+	switch cfg.Driver {
+	case config.DriverMemory:
+		logger.Warn("using in-memory storage, data will not survive restarts")
+		repo := repository.NewMemoryURLRepository(logger)
+		return &stores{
+			urls:         repo,
+			clicks:       repository.NewMemoryClickRepository(logger),
+			idempotency:  repository.NewMemoryIdempotencyRepository(logger),
+			utmTemplates: repository.NewMemoryUTMTemplateRepository(logger),
+			schedules:    repository.NewMemoryScheduleRepository(logger),
+			apiKeys:      repository.NewMemoryAPIKeyRepository(logger),
+			health:       repo,
+			close:        func() {},
+		}, nil
+	case config.DriverSQLite:
+		db, err := storage.NewSQLiteDB(ctx, cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		// SQLite is single-node, so its schema is always kept current on startup.
+		if err := autoMigrate(ctx, db.DB(), migration.SQLite, logger); err != nil {
+			db.Close()
+			return nil, err
+		}
+		return &stores{
+			urls:         repository.NewSQLiteURLRepository(db.DB(), logger),
+			clicks:       repository.NewSQLiteClickRepository(db.DB(), logger),
+			idempotency:  repository.NewSQLiteIdempotencyRepository(db.DB(), logger),
+			utmTemplates: repository.NewSQLiteUTMTemplateRepository(db.DB(), logger),
+			schedules:    repository.NewSQLiteScheduleRepository(db.DB(), logger),
+			apiKeys:      repository.NewSQLiteAPIKeyRepository(db.DB(), logger),
+			health:       db,
+			close:        db.Close,
+		}, nil
+	default:
+		db, err := storage.NewPostgresDB(ctx, cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.AutoMigrate {
+			if err := autoMigrate(ctx, db.SQLDB(), migration.Postgres, logger); err != nil {
+				db.Close()
+				return nil, err
+			}
+		}
+		return &stores{
+			urls:         repository.NewURLRepository(db.Pool(), logger),
+			clicks:       repository.NewClickRepository(db.Pool(), logger),
+			idempotency:  repository.NewIdempotencyRepository(db.Pool(), logger),
+			utmTemplates: repository.NewUTMTemplateRepository(db.Pool(), logger),
+			schedules:    repository.NewScheduleRepository(db.Pool(), logger),
+			apiKeys:      repository.NewAPIKeyRepository(db.Pool(), logger),
+			health:       db,
+			close:        db.Close,
+		}, nil
+	}
+}
+
+func startCleanupWorker(ctx context.Context, urlService *service.URLService, idempotencyService *service.IdempotencyService, logger *slog.Logger) {
 	ticker := time.NewTicker(1 * time.Hour)
 	defer ticker.Stop()
 
@@ -144,6 +284,51 @@ func startCleanupWorker(ctx context.Context, urlService *service.URLService, log
 			} else if count > 0 {
 				logger.Info("cleanup completed", slog.Int64("deleted", count))
 			}
+
+			cleanupCtx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
+			purged, err := urlService.PurgeDeleted(cleanupCtx)
+			cancel()
+
+			if err != nil {
+				logger.Error("deleted url purge failed", slog.String("error", err.Error()))
+			} else if purged > 0 {
+				logger.Info("deleted url purge completed", slog.Int64("purged", purged))
+			}
+
+			cleanupCtx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
+			keys, err := idempotencyService.CleanupExpired(cleanupCtx)
+			cancel()
+
+			if err != nil {
+				logger.Error("idempotency key cleanup failed", slog.String("error", err.Error()))
+			} else if keys > 0 {
+				logger.Info("idempotency key cleanup completed", slog.Int64("deleted", keys))
+			}
+		}
+	}
+}
+
+func startScheduler(ctx context.Context, scheduleService *service.ScheduleService, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logger.Info("scheduler started", slog.Duration("interval", interval))
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("scheduler stopped")
+			return
+		case <-ticker.C:
+			applyCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			count, err := scheduleService.ApplyDue(applyCtx)
+			cancel()
+
+			if err != nil {
+				logger.Error("applying scheduled changes failed", slog.String("error", err.Error()))
+			} else if count > 0 {
+				logger.Info("scheduled changes applied", slog.Int("applied", count))
+			}
 		}
 	}
 }
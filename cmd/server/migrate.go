@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/config"
+	"github.com/edson-mazvila/url-shortener/internal/migration"
+	"github.com/edson-mazvila/url-shortener/internal/storage"
+)
+
+const migrationTimeout = 5 * time.Minute
+
+const migrateUsage = "usage: server migrate up|down|status|to <version>"
+
+// runMigrate implements the `server migrate` subcommand.
+func runMigrate(cfg *config.DatabaseConfig, logger *slog.Logger, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), migrationTimeout)
+	defer cancel()
+
+	migrator, closeDB, err := openMigrator(ctx, cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	switch args[0] {
+	case "up":
+		count, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("applied %d migration(s)\n", count)
+	case "down":
+		count, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("reverted %d migration(s)\n", count)
+	case "to":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid migration version %q: %w", args[1], err)
+		}
+		count, err := migrator.To(ctx, version)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("migrated to version %d (%d migration(s) run)\n", version, count)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printMigrationStatus(statuses)
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	return nil
+}
+
+func openMigrator(ctx context.Context, cfg *config.DatabaseConfig, logger *slog.Logger) (*migration.Migrator, func(), error) {
+	switch cfg.Driver {
+	case config.DriverPostgres:
+		db, err := storage.NewPostgresDB(ctx, cfg, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		migrator, err := migration.NewMigrator(db.SQLDB(), migration.Postgres, logger)
+		if err != nil {
+			db.Close()
+			return nil, nil, err
+		}
+		return migrator, db.Close, nil
+	case config.DriverSQLite:
+		db, err := storage.NewSQLiteDB(ctx, cfg, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		migrator, err := migration.NewMigrator(db.DB(), migration.SQLite, logger)
+		if err != nil {
+			db.Close()
+			return nil, nil, err
+		}
+		return migrator, db.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("migrations are not supported by the %s driver", cfg.Driver)
+	}
+}
+
+// autoMigrate applies pending migrations during startup.
+func autoMigrate(ctx context.Context, db *sql.DB, dialect migration.Dialect, logger *slog.Logger) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), migrationTimeout)
+	defer cancel()
+
+	migrator, err := migration.NewMigrator(db, dialect, logger)
+	if err != nil {
+		return err
+	}
+
+	count, err := migrator.Up(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	logger.Info("database schema up to date",
+		slog.String("driver", dialect.Name),
+		slog.Int("applied", count),
+		slog.Int64("version", migrator.Latest()),
+	)
+
+	return nil
+}
+
+func printMigrationStatus(statuses []migration.Status) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		state, appliedAt := "pending", "-"
+		if s.Applied {
+			state = "applied"
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+	}
+	w.Flush()
+}
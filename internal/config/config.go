@@ -9,12 +9,35 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// Supported storage drivers.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory"
+)
+
+// SQLiteMemoryPath is the SQLite database path that opens a private in-memory database per connection.
+const SQLiteMemoryPath = ":memory:"
+
+// Supported short code generation strategies.
+const (
+	CodeStrategyRandom     = "random"
+	CodeStrategySequential = "sequential"
+	CodeStrategyHashids    = "hashids"
+	CodeStrategyWords      = "words"
+)
+
 // Config holds all configuration for the application.
 type Config struct {
-	Server   ServerConfig   `yaml:"server"`
-	Database DatabaseConfig `yaml:"database"`
-	URL      URLConfig      `yaml:"url"`
-	Logging  LoggingConfig  `yaml:"logging"`
+	Server    ServerConfig    `yaml:"server"`
+	Database  DatabaseConfig  `yaml:"database"`
+	URL       URLConfig       `yaml:"url"`
+	Analytics AnalyticsConfig `yaml:"analytics"`
+	Cache     CacheConfig     `yaml:"cache"`
+	GeoIP     GeoIPConfig     `yaml:"geoip"`
+	Pages     PagesConfig     `yaml:"pages"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Logging   LoggingConfig   `yaml:"logging"`
 }
 
 // ServerConfig contains HTTP server configuration.
@@ -29,6 +52,9 @@ type ServerConfig struct {
 
 // DatabaseConfig contains database connection configuration.
 type DatabaseConfig struct {
+	Driver          string        `yaml:"driver"`
+	Path            string        `yaml:"path"`
+	AutoMigrate     bool          `yaml:"auto_migrate"`
 	Host            string        `yaml:"host"`
 	Port            int           `yaml:"port"`
 	User            string        `yaml:"user"`
@@ -43,9 +69,60 @@ type DatabaseConfig struct {
 
 // URLConfig contains URL shortening specific configuration.
 type URLConfig struct {
-	ShortCodeLength int           `yaml:"short_code_length"`
-	DefaultTTL      time.Duration `yaml:"default_ttl"`
-	BaseURL         string        `yaml:"base_url"`
+	ShortCodeLength     int           `yaml:"short_code_length"`
+	DefaultTTL          time.Duration `yaml:"default_ttl"`
+	BaseURL             string        `yaml:"base_url"`
+	CodeStrategy        string        `yaml:"code_strategy"`
+	CodeAlphabet        string        `yaml:"code_alphabet"`
+	CodeSalt            string        `yaml:"code_salt"`
+	IdempotencyTTL      time.Duration `yaml:"idempotency_ttl"`
+	DefaultRedirectType int           `yaml:"default_redirect_type"`
+	RedirectCacheMaxAge time.Duration `yaml:"redirect_cache_max_age"`
+	QueryPrecedence     string        `yaml:"query_precedence"`
+	VariantCookieTTL    time.Duration `yaml:"variant_cookie_ttl"`
+	PasswordMaxAttempts int           `yaml:"password_max_attempts"`
+	PasswordLockout     time.Duration `yaml:"password_lockout"`
+	NotLiveStatus       int           `yaml:"not_live_status"`
+	SchedulerInterval   time.Duration `yaml:"scheduler_interval"`
+	DeleteQuarantine    time.Duration `yaml:"delete_quarantine"`
+}
+
+// AnalyticsConfig contains click tracking configuration.
+type AnalyticsConfig struct {
+	FlushInterval time.Duration `yaml:"flush_interval"`
+	MaxPending    int           `yaml:"max_pending"`
+	QueueSize     int           `yaml:"queue_size"`
+	BatchSize     int           `yaml:"batch_size"`
+}
+
+// CacheConfig contains short code lookup cache configuration.
+type CacheConfig struct {
+	Enabled bool `yaml:"enabled"`
+	Size    int  `yaml:"size"`
+	// TTL bounds how long other replicas keep redirecting with a link's old settings after it is
+	// edited, disabled or deleted; each process only evicts entries for its own writes.
+	TTL         time.Duration `yaml:"ttl"`
+	NegativeTTL time.Duration `yaml:"negative_ttl"`
+}
+
+// GeoIPConfig contains client location lookup configuration.
+type GeoIPConfig struct {
+	// DatabasePath is a MaxMind-format .mmdb file; empty disables geo targeting.
+	DatabasePath string `yaml:"database_path"`
+}
+
+// PagesConfig contains configuration of the HTML pages shown to visitors.
+type PagesConfig struct {
+	// TemplateDir holds templates that replace the built-in ones of the same name; empty uses only the
+	// built-in templates.
+	TemplateDir string `yaml:"template_dir"`
+}
+
+// AuthConfig contains management API authentication configuration.
+type AuthConfig struct {
+	// BootstrapKey is an admin API key accepted in addition to the stored keys, so the first keys can be
+	// created; empty accepts only stored keys.
+	BootstrapKey string `yaml:"bootstrap_key"`
 }
 
 // LoggingConfig contains logging configuration.
@@ -66,6 +143,9 @@ func Load() (*Config, error) {
 			ShutdownTimeout: getEnvAsDuration("SERVER_SHUTDOWN_TIMEOUT", 30*time.Second),
 		},
 		Database: DatabaseConfig{
+			Driver:          getEnv("DB_DRIVER", DriverPostgres),
+			Path:            getEnv("DB_PATH", "urlshortener.db"),
+			AutoMigrate:     getEnvAsBool("DB_AUTO_MIGRATE", false),
 			Host:            getEnv("DB_HOST", "localhost"),
 			Port:            getEnvAsInt("DB_PORT", 5432),
 			User:            getEnv("DB_USER", "postgres"),
@@ -78,9 +158,43 @@ func Load() (*Config, error) {
 			ConnMaxIdleTime: getEnvAsDuration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
 		},
 		URL: URLConfig{
-			ShortCodeLength: getEnvAsInt("URL_SHORT_CODE_LENGTH", 7),
-			DefaultTTL:      getEnvAsDuration("URL_DEFAULT_TTL", 0),
-			BaseURL:         getEnv("URL_BASE_URL", "http://localhost:8080"),
+			ShortCodeLength:     getEnvAsInt("URL_SHORT_CODE_LENGTH", 7),
+			DefaultTTL:          getEnvAsDuration("URL_DEFAULT_TTL", 0),
+			BaseURL:             getEnv("URL_BASE_URL", "http://localhost:8080"),
+			CodeStrategy:        getEnv("URL_CODE_STRATEGY", CodeStrategyRandom),
+			CodeAlphabet:        getEnv("URL_CODE_ALPHABET", ""),
+			CodeSalt:            getEnv("URL_CODE_SALT", ""),
+			IdempotencyTTL:      getEnvAsDuration("URL_IDEMPOTENCY_TTL", 24*time.Hour),
+			DefaultRedirectType: getEnvAsInt("URL_DEFAULT_REDIRECT_TYPE", 302),
+			RedirectCacheMaxAge: getEnvAsDuration("URL_REDIRECT_CACHE_MAX_AGE", 24*time.Hour),
+			QueryPrecedence:     getEnv("URL_QUERY_PRECEDENCE", "destination"),
+			VariantCookieTTL:    getEnvAsDuration("URL_VARIANT_COOKIE_TTL", 30*24*time.Hour),
+			PasswordMaxAttempts: getEnvAsInt("URL_PASSWORD_MAX_ATTEMPTS", 5),
+			PasswordLockout:     getEnvAsDuration("URL_PASSWORD_LOCKOUT", 15*time.Minute),
+			NotLiveStatus:       getEnvAsInt("URL_NOT_LIVE_STATUS", 404),
+			SchedulerInterval:   getEnvAsDuration("URL_SCHEDULER_INTERVAL", time.Minute),
+			DeleteQuarantine:    getEnvAsDuration("URL_DELETE_QUARANTINE", 30*24*time.Hour),
+		},
+		Analytics: AnalyticsConfig{
+			FlushInterval: getEnvAsDuration("ANALYTICS_FLUSH_INTERVAL", 5*time.Second),
+			MaxPending:    getEnvAsInt("ANALYTICS_MAX_PENDING", 1000),
+			QueueSize:     getEnvAsInt("ANALYTICS_QUEUE_SIZE", 10000),
+			BatchSize:     getEnvAsInt("ANALYTICS_BATCH_SIZE", 100),
+		},
+		Cache: CacheConfig{
+			Enabled:     getEnvAsBool("CACHE_ENABLED", true),
+			Size:        getEnvAsInt("CACHE_SIZE", 10000),
+			TTL:         getEnvAsDuration("CACHE_TTL", 5*time.Minute),
+			NegativeTTL: getEnvAsDuration("CACHE_NEGATIVE_TTL", 30*time.Second),
+		},
+		GeoIP: GeoIPConfig{
+			DatabasePath: getEnv("GEOIP_DATABASE_PATH", ""),
+		},
+		Pages: PagesConfig{
+			TemplateDir: getEnv("PAGES_TEMPLATE_DIR", ""),
+		},
+		Auth: AuthConfig{
+			BootstrapKey: getEnv("AUTH_BOOTSTRAP_KEY", ""),
 		},
 		Logging: LoggingConfig{
 			Level:  getEnv("LOG_LEVEL", "info"),
@@ -109,28 +223,99 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid server port: %d", c.Server.Port)
 	}
 
-	if c.Database.Host == "" {
-		return fmt.Errorf("database host is required")
+	if err := c.Database.Validate(); err != nil {
+		return err
 	}
 
-	if c.Database.Port < 1 || c.Database.Port > 65535 {
-		return fmt.Errorf("invalid database port: %d", c.Database.Port)
+	if c.URL.ShortCodeLength < 4 || c.URL.ShortCodeLength > 16 {
+		return fmt.Errorf("short code length must be between 4 and 16")
 	}
 
-	if c.Database.User == "" {
-		return fmt.Errorf("database user is required")
+	if c.URL.BaseURL == "" {
+		return fmt.Errorf("base URL is required")
 	}
 
-	if c.Database.Database == "" {
-		return fmt.Errorf("database name is required")
+	switch c.URL.CodeStrategy {
+	case CodeStrategyRandom, CodeStrategySequential, CodeStrategyHashids, CodeStrategyWords:
+	default:
+		return fmt.Errorf("invalid code strategy: %s", c.URL.CodeStrategy)
 	}
 
-	if c.URL.ShortCodeLength < 4 || c.URL.ShortCodeLength > 16 {
-		return fmt.Errorf("short code length must be between 4 and 16")
+	for _, char := range c.URL.CodeAlphabet {
+		if !((char >= 'a' && char <= 'z') ||
+			(char >= 'A' && char <= 'Z') ||
+			(char >= '0' && char <= '9') ||
+			char == '-' || char == '_') {
+			return fmt.Errorf("invalid character %q in code alphabet", char)
+		}
 	}
 
-	if c.URL.BaseURL == "" {
-		return fmt.Errorf("base URL is required")
+	if c.URL.IdempotencyTTL <= 0 {
+		return fmt.Errorf("idempotency ttl must be positive")
+	}
+
+	switch c.URL.DefaultRedirectType {
+	case 301, 302, 307, 308:
+	default:
+		return fmt.Errorf("invalid default redirect type: %d", c.URL.DefaultRedirectType)
+	}
+
+	if c.URL.RedirectCacheMaxAge < 0 {
+		return fmt.Errorf("redirect cache max age cannot be negative")
+	}
+
+	if c.URL.QueryPrecedence != "destination" && c.URL.QueryPrecedence != "request" {
+		return fmt.Errorf("invalid query precedence: %s", c.URL.QueryPrecedence)
+	}
+
+	if c.URL.VariantCookieTTL <= 0 {
+		return fmt.Errorf("variant cookie ttl must be positive")
+	}
+
+	if c.URL.PasswordMaxAttempts <= 0 {
+		return fmt.Errorf("password max attempts must be positive")
+	}
+
+	if c.URL.PasswordLockout <= 0 {
+		return fmt.Errorf("password lockout must be positive")
+	}
+
+	switch c.URL.NotLiveStatus {
+	case 403, 404, 425, 503:
+	default:
+		return fmt.Errorf("invalid not live status: %d", c.URL.NotLiveStatus)
+	}
+
+	if c.URL.SchedulerInterval <= 0 {
+		return fmt.Errorf("scheduler interval must be positive")
+	}
+
+	if c.URL.DeleteQuarantine < 0 {
+		return fmt.Errorf("delete quarantine cannot be negative")
+	}
+
+	if c.Analytics.FlushInterval <= 0 {
+		return fmt.Errorf("analytics flush interval must be positive")
+	}
+
+	if c.Analytics.MaxPending < 1 {
+		return fmt.Errorf("analytics max pending must be at least 1")
+	}
+
+	if c.Analytics.QueueSize < 1 {
+		return fmt.Errorf("analytics queue size must be at least 1")
+	}
+
+	if c.Analytics.BatchSize < 1 {
+		return fmt.Errorf("analytics batch size must be at least 1")
+	}
+
+	if c.Cache.Enabled && c.Cache.Size < 1 {
+		return fmt.Errorf("cache size must be at least 1")
+	}
+
+	if c.Auth.BootstrapKey != "" && len(c.Auth.BootstrapKey) < 32 {
+		return fmt.Errorf("auth bootstrap key must be at least 32 characters")
 	}
 
 	validLogLevels := map[string]bool{
@@ -147,6 +332,42 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// Validate validates the database configuration for the selected driver.
+func (d *DatabaseConfig) Validate() error {
+	switch d.Driver {
+	case DriverPostgres:
+		if d.Host == "" {
+			return fmt.Errorf("database host is required")
+		}
+
+		if d.Port < 1 || d.Port > 65535 {
+			return fmt.Errorf("invalid database port: %d", d.Port)
+		}
+
+		if d.User == "" {
+			return fmt.Errorf("database user is required")
+		}
+
+		if d.Database == "" {
+			return fmt.Errorf("database name is required")
+		}
+	case DriverSQLite:
+		if d.Path == "" {
+			return fmt.Errorf("database path is required for sqlite")
+		}
+
+		// Every connection to :memory: gets its own empty database, so the pool must hold exactly one.
+		if d.Path == SQLiteMemoryPath && d.MaxOpenConns != 1 {
+			return fmt.Errorf("sqlite path %s requires max open connections of 1", SQLiteMemoryPath)
+		}
+	case DriverMemory:
+	default:
+		return fmt.Errorf("invalid database driver: %s", d.Driver)
+	}
+
+	return nil
+}
+
 // GetDSN returns the PostgreSQL connection string.
 func (d *DatabaseConfig) GetDSN() string {
 	return fmt.Sprintf(
@@ -189,6 +410,20 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return value
 }
 
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
+
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	valueStr := os.Getenv(key)
 	if valueStr == "" {
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// Click represents a single successful redirect through a short URL.
+type Click struct {
+	ID         int64     `json:"id"`
+	URLID      int64     `json:"url_id"`
+	ShortCode  string    `json:"short_code"`
+	ClickedAt  time.Time `json:"clicked_at"`
+	Referrer   string    `json:"referrer,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	RequestID  string    `json:"request_id,omitempty"`
+	TargetRule string    `json:"target_rule,omitempty"`
+	Country    string    `json:"country,omitempty"`
+	Variant    string    `json:"variant,omitempty"`
+}
@@ -9,6 +9,24 @@ var (
 	// ErrURLExpired is returned when a URL has expired.
 	ErrURLExpired = errors.New("url has expired")
 
+	// ErrClickLimitReached is returned when a URL has used up its maximum number of clicks.
+	ErrClickLimitReached = errors.New("url click limit reached")
+
+	// ErrURLDisabled is returned when a disabled URL is visited.
+	ErrURLDisabled = errors.New("url is disabled")
+
+	// ErrURLNotActive is returned when a URL is visited before its activation time.
+	ErrURLNotActive = errors.New("url is not active yet")
+
+	// ErrInvalidActivation is returned when an activation time cannot be applied.
+	ErrInvalidActivation = errors.New("invalid activation time")
+
+	// ErrScheduleNotFound is returned when a URL has no scheduled change with the requested ID.
+	ErrScheduleNotFound = errors.New("scheduled change not found")
+
+	// ErrInvalidSchedule is returned when a scheduled change is invalid.
+	ErrInvalidSchedule = errors.New("invalid scheduled change")
+
 	// ErrInvalidURL is returned when the provided URL is invalid.
 	ErrInvalidURL = errors.New("invalid url")
 
@@ -17,4 +35,76 @@ var (
 
 	// ErrInvalidShortCode is returned when the short code format is invalid.
 	ErrInvalidShortCode = errors.New("invalid short code")
+
+	// ErrInvalidStatsQuery is returned when a stats time range or interval is invalid.
+	ErrInvalidStatsQuery = errors.New("invalid stats query")
+
+	// ErrIdempotencyKeyNotFound is returned when an idempotency key has no stored record.
+	ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
+
+	// ErrIdempotencyKeyExists is returned when an idempotency key is already reserved.
+	ErrIdempotencyKeyExists = errors.New("idempotency key already exists")
+
+	// ErrIdempotencyKeyInProgress is returned when a request with the same idempotency key is still being processed.
+	ErrIdempotencyKeyInProgress = errors.New("request with this idempotency key is in progress")
+
+	// ErrIdempotencyKeyReused is returned when an idempotency key is reused for a different request.
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with a different request")
+
+	// ErrInvalidIdempotencyKey is returned when the idempotency key format is invalid.
+	ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+
+	// ErrVersionMismatch is returned when a URL was modified since the version the caller last read.
+	ErrVersionMismatch = errors.New("url was modified by another request")
+
+	// ErrInvalidExpiry is returned when a requested expiry change cannot be applied.
+	ErrInvalidExpiry = errors.New("invalid expiry")
+
+	// ErrRevisionNotFound is returned when a URL has no revision with the requested version.
+	ErrRevisionNotFound = errors.New("revision not found")
+
+	// ErrInvalidRedirectType is returned when a redirect type is not a supported redirect status code.
+	ErrInvalidRedirectType = errors.New("invalid redirect type")
+
+	// ErrInvalidQueryPrecedence is returned when a query precedence is not supported.
+	ErrInvalidQueryPrecedence = errors.New("invalid query precedence")
+
+	// ErrUTMTemplateNotFound is returned when a UTM template cannot be found.
+	ErrUTMTemplateNotFound = errors.New("utm template not found")
+
+	// ErrUTMTemplateExists is returned when a UTM template name is already in use.
+	ErrUTMTemplateExists = errors.New("utm template already exists")
+
+	// ErrInvalidUTM is returned when a UTM template or UTM parameters are invalid.
+	ErrInvalidUTM = errors.New("invalid utm parameters")
+
+	// ErrInvalidTargetingRule is returned when a targeting rule is invalid.
+	ErrInvalidTargetingRule = errors.New("invalid targeting rule")
+
+	// ErrInvalidVariant is returned when a link's destination variants are invalid.
+	ErrInvalidVariant = errors.New("invalid variant")
+
+	// ErrInvalidPassword is returned when a new link password does not meet the requirements.
+	ErrInvalidPassword = errors.New("invalid password")
+
+	// ErrInvalidClickLimit is returned when a maximum number of clicks is not positive.
+	ErrInvalidClickLimit = errors.New("invalid click limit")
+
+	// ErrPasswordRequired is returned when a protected link is visited without a password.
+	ErrPasswordRequired = errors.New("password required")
+
+	// ErrIncorrectPassword is returned when the password entered for a protected link is wrong.
+	ErrIncorrectPassword = errors.New("incorrect password")
+
+	// ErrTooManyPasswordAttempts is returned when a client entered too many wrong passwords for a link.
+	ErrTooManyPasswordAttempts = errors.New("too many password attempts")
+
+	// ErrAPIKeyNotFound is returned when an API key cannot be found.
+	ErrAPIKeyNotFound = errors.New("api key not found")
+
+	// ErrInvalidAPIKey is returned when a request presents an unknown or revoked API key.
+	ErrInvalidAPIKey = errors.New("invalid api key")
+
+	// ErrInvalidAPIKeyName is returned when an API key name is empty or too long.
+	ErrInvalidAPIKeyName = errors.New("invalid api key name")
 )
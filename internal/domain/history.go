@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// Revision actions recorded in a URL's history.
+const (
+	RevisionCreate   = "create"
+	RevisionUpdate   = "update"
+	RevisionRollback = "rollback"
+	RevisionDisable  = "disable"
+	RevisionEnable   = "enable"
+	RevisionDelete   = "delete"
+	RevisionRestore  = "restore"
+)
+
+// Change identifies who made a change to a URL and how; it is recorded in the URL's history.
+type Change struct {
+	Action string
+	Actor  string
+	// RestoredVersion is the version restored by a rollback.
+	RestoredVersion int64
+}
+
+// URLRevision is the state of a URL's destination and expiry after one change.
+type URLRevision struct {
+	ID              int64      `json:"-"`
+	URLID           int64      `json:"-"`
+	Version         int64      `json:"version"`
+	Action          string     `json:"action"`
+	OriginalURL     string     `json:"original_url"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+	RestoredVersion *int64     `json:"restored_version,omitempty"`
+	ChangedBy       string     `json:"changed_by"`
+	ChangedAt       time.Time  `json:"changed_at"`
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// IdempotencyRecord stores the outcome of a request sent with an Idempotency-Key header.
+// Keys are scoped to the API key that sent the request.
+type IdempotencyRecord struct {
+	APIKeyID     int64
+	Key          string
+	RequestHash  string
+	StatusCode   int
+	ResponseBody []byte
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+// Completed reports whether the response of the original request has been stored.
+func (r *IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
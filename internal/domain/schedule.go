@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// ScheduledChange is a destination change that the scheduler applies to a URL once ApplyAt has passed.
+type ScheduledChange struct {
+	ID          int64     `json:"id"`
+	URLID       int64     `json:"-"`
+	ShortCode   string    `json:"short_code"`
+	OriginalURL string    `json:"url"`
+	ApplyAt     time.Time `json:"apply_at"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,88 @@
+package domain
+
+import "time"
+
+// StatsInterval is the bucket width of a click time series.
+type StatsInterval string
+
+// Supported stats intervals.
+const (
+	IntervalHour StatsInterval = "hour"
+	IntervalDay  StatsInterval = "day"
+	IntervalWeek StatsInterval = "week"
+)
+
+// Valid reports whether the interval is supported.
+func (i StatsInterval) Valid() bool {
+	switch i {
+	case IntervalHour, IntervalDay, IntervalWeek:
+		return true
+	}
+	return false
+}
+
+// Truncate returns the start of the UTC bucket containing t. Weeks start on Monday.
+func (i StatsInterval) Truncate(t time.Time) time.Time {
+	t = t.UTC()
+	switch i {
+	case IntervalHour:
+		return t.Truncate(time.Hour)
+	case IntervalWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// Next returns the start of the bucket following the one starting at t.
+func (i StatsInterval) Next(t time.Time) time.Time {
+	switch i {
+	case IntervalHour:
+		return t.Add(time.Hour)
+	case IntervalWeek:
+		return t.AddDate(0, 0, 7)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// ClickStatsQuery selects the clicks of one URL within [From, To).
+type ClickStatsQuery struct {
+	URLID    int64
+	From     time.Time
+	To       time.Time
+	Interval StatsInterval
+	TopN     int
+}
+
+// ClickBucket is a single point of a click time series.
+type ClickBucket struct {
+	Start          time.Time `json:"start"`
+	Clicks         int64     `json:"clicks"`
+	UniqueVisitors int64     `json:"unique_visitors"`
+}
+
+// CountEntry is a value together with the number of clicks it occurred in.
+type CountEntry struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// ClickStats aggregates the clicks of one URL over a time range.
+type ClickStats struct {
+	ShortCode         string        `json:"short_code"`
+	From              time.Time     `json:"from"`
+	To                time.Time     `json:"to"`
+	Interval          StatsInterval `json:"interval"`
+	TotalClicks       int64         `json:"total_clicks"`
+	UniqueVisitors    int64         `json:"unique_visitors"`
+	Series            []ClickBucket `json:"series"`
+	TopReferrers      []CountEntry  `json:"top_referrers"`
+	TopUserAgents     []CountEntry  `json:"top_user_agents"`
+	TopBrowsers       []CountEntry  `json:"top_browsers"`
+	TopTargetingRules []CountEntry  `json:"top_targeting_rules"`
+	TopCountries      []CountEntry  `json:"top_countries"`
+	Variants          []CountEntry  `json:"variants"`
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"slices"
+
+	"github.com/edson-mazvila/url-shortener/internal/useragent"
+)
+
+// TargetingRule sends visitors matching every set condition to URL instead of the link's original
+// URL. Empty conditions match any value; Countries and Regions match any of their entries.
+type TargetingRule struct {
+	Name      string   `json:"name"`
+	OS        string   `json:"os,omitempty"`
+	Device    string   `json:"device,omitempty"`
+	Browser   string   `json:"browser,omitempty"`
+	Countries []string `json:"countries,omitempty"`
+	Regions   []string `json:"regions,omitempty"`
+	URL       string   `json:"url"`
+}
+
+// Visitor holds the attributes targeting rules are evaluated against.
+type Visitor struct {
+	useragent.Info
+	// Country and Region are ISO 3166 codes, empty when the location is unknown.
+	Country string
+	Region  string
+}
+
+// Matches reports whether visitor satisfies every condition of the rule.
+func (r TargetingRule) Matches(visitor Visitor) bool {
+	return (r.OS == "" || r.OS == visitor.OS) &&
+		(r.Device == "" || r.Device == visitor.Device) &&
+		(r.Browser == "" || r.Browser == visitor.Browser) &&
+		(len(r.Countries) == 0 || (visitor.Country != "" && slices.Contains(r.Countries, visitor.Country))) &&
+		(len(r.Regions) == 0 || (visitor.Region != "" && slices.Contains(r.Regions, visitor.Region)))
+}
@@ -1,16 +1,99 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
 
 // URL represents a shortened URL entity in the system.
 type URL struct {
-	ID           int64      `json:"id"`
-	ShortCode    string     `json:"short_code"`
-	OriginalURL  string     `json:"original_url"`
-	CreatedAt    time.Time  `json:"created_at"`
-	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
-	AccessCount  int64      `json:"access_count"`
-	LastAccessed *time.Time `json:"last_accessed,omitempty"`
+	ID            int64      `json:"id"`
+	ShortCode     string     `json:"short_code"`
+	OriginalURL   string     `json:"original_url"`
+	NormalizedURL string     `json:"-"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	// ActivatesAt is the time before which the link does not redirect; nil means it is live immediately.
+	ActivatesAt *time.Time `json:"activates_at,omitempty"`
+	// DisabledAt is the time the link was disabled; disabled links do not redirect until enabled again.
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	// DeletedAt is the time the link was soft-deleted. Its short code stays reserved until it is purged.
+	DeletedAt        *time.Time      `json:"deleted_at,omitempty"`
+	AccessCount      int64           `json:"access_count"`
+	LastAccessed     *time.Time      `json:"last_accessed,omitempty"`
+	Version          int64           `json:"version"`
+	RedirectType     int             `json:"redirect_type,omitempty"`
+	QueryPassthrough bool            `json:"query_passthrough,omitempty"`
+	QueryPrecedence  string          `json:"query_precedence,omitempty"`
+	PathPassthrough  bool            `json:"path_passthrough,omitempty"`
+	BaseURL          string          `json:"base_url,omitempty"`
+	UTM              *UTMParams      `json:"utm,omitempty"`
+	TargetingRules   []TargetingRule `json:"targeting_rules,omitempty"`
+	Variants         []Variant       `json:"variants,omitempty"`
+	StickyVariants   bool            `json:"sticky_variants,omitempty"`
+	// PasswordHash is the bcrypt hash of the password visitors must enter, empty for public links.
+	PasswordHash string `json:"-"`
+	// MaxClicks is the number of redirects after which the link stops working; nil means unlimited.
+	MaxClicks *int64 `json:"max_clicks,omitempty"`
+	// UsedClicks counts the redirects made against MaxClicks.
+	UsedClicks int64 `json:"-"`
+	// AlwaysPreview shows visitors a preview of the destination instead of redirecting them straight away.
+	AlwaysPreview bool `json:"always_preview,omitempty"`
+	// FallbackURL is where visitors are sent instead of an error once the link has expired, used up its
+	// clicks, been disabled, or before it is live; empty shows the error.
+	FallbackURL string `json:"fallback_url,omitempty"`
+}
+
+// MarshalJSON encodes the URL with a password_protected flag in place of its password hash, and with
+// the redirects it has left.
+func (u URL) MarshalJSON() ([]byte, error) {
+	type plainURL URL
+	return json.Marshal(struct {
+		plainURL
+		PasswordProtected bool   `json:"password_protected,omitempty"`
+		RemainingClicks   *int64 `json:"remaining_clicks,omitempty"`
+	}{plainURL(u), u.IsPasswordProtected(), u.RemainingClicks()})
+}
+
+// RemainingClicks returns how many more redirects the link allows, or nil if it has no click limit.
+func (u *URL) RemainingClicks() *int64 {
+	if u.MaxClicks == nil {
+		return nil
+	}
+	remaining := max(*u.MaxClicks-u.UsedClicks, 0)
+	return &remaining
+}
+
+// IsExhausted reports whether the link has used up its click limit.
+func (u *URL) IsExhausted() bool {
+	return u.MaxClicks != nil && u.UsedClicks >= *u.MaxClicks
+}
+
+// IsPasswordProtected reports whether visitors must enter a password to follow the link.
+func (u *URL) IsPasswordProtected() bool {
+	return u.PasswordHash != ""
+}
+
+// Query parameter precedence when a visit and the destination set the same parameter.
+const (
+	QueryPrecedenceDestination = "destination"
+	QueryPrecedenceRequest     = "request"
+)
+
+// IsValidQueryPrecedence reports whether precedence is a supported query precedence.
+func IsValidQueryPrecedence(precedence string) bool {
+	return precedence == QueryPrecedenceDestination || precedence == QueryPrecedenceRequest
+}
+
+// IsValidRedirectType reports whether code is a status code links may redirect with.
+func IsValidRedirectType(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	default:
+		return false
+	}
 }
 
 // IsExpired checks if the URL has expired.
@@ -21,6 +104,21 @@ func (u *URL) IsExpired() bool {
 	return time.Now().After(*u.ExpiresAt)
 }
 
+// IsActive reports whether the link has reached its activation time.
+func (u *URL) IsActive() bool {
+	return u.ActivatesAt == nil || !time.Now().Before(*u.ActivatesAt)
+}
+
+// IsDisabled reports whether the link has been disabled.
+func (u *URL) IsDisabled() bool {
+	return u.DisabledAt != nil
+}
+
+// IsDeleted reports whether the link has been soft-deleted.
+func (u *URL) IsDeleted() bool {
+	return u.DeletedAt != nil
+}
+
 // IncrementAccessCount increments the access counter.
 func (u *URL) IncrementAccessCount() {
 	u.AccessCount++
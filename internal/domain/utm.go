@@ -0,0 +1,75 @@
+package domain
+
+import "time"
+
+// UTMParams holds the campaign tracking parameters appended to a destination.
+type UTMParams struct {
+	Source   string `json:"source,omitempty"`
+	Medium   string `json:"medium,omitempty"`
+	Campaign string `json:"campaign,omitempty"`
+	Term     string `json:"term,omitempty"`
+	Content  string `json:"content,omitempty"`
+}
+
+// UTMTemplate is a named, reusable set of UTM parameters.
+type UTMTemplate struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	UTMParams
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IsZero reports whether no parameter is set.
+func (p UTMParams) IsZero() bool {
+	return p == UTMParams{}
+}
+
+// Merge returns p with every parameter that is set in override replaced.
+func (p UTMParams) Merge(override UTMParams) UTMParams {
+	if override.Source != "" {
+		p.Source = override.Source
+	}
+	if override.Medium != "" {
+		p.Medium = override.Medium
+	}
+	if override.Campaign != "" {
+		p.Campaign = override.Campaign
+	}
+	if override.Term != "" {
+		p.Term = override.Term
+	}
+	if override.Content != "" {
+		p.Content = override.Content
+	}
+	return p
+}
+
+// QueryParams returns the parameters keyed by their utm_* query parameter names.
+func (p UTMParams) QueryParams() map[string]string {
+	return map[string]string{
+		"utm_source":   p.Source,
+		"utm_medium":   p.Medium,
+		"utm_campaign": p.Campaign,
+		"utm_term":     p.Term,
+		"utm_content":  p.Content,
+	}
+}
+
+// UTMParamsFromQuery returns the UTM parameters set in query.
+func UTMParamsFromQuery(query map[string][]string) UTMParams {
+	first := func(key string) string {
+		if values := query[key]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	return UTMParams{
+		Source:   first("utm_source"),
+		Medium:   first("utm_medium"),
+		Campaign: first("utm_campaign"),
+		Term:     first("utm_term"),
+		Content:  first("utm_content"),
+	}
+}
@@ -0,0 +1,9 @@
+package domain
+
+// Variant is one of the destinations a link splits its traffic across. Each visit is sent to a variant
+// with a probability proportional to its weight.
+type Variant struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Weight int    `json:"weight"`
+}
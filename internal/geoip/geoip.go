@@ -0,0 +1,87 @@
+package geoip
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Location is the place an IP address was resolved to. Fields are empty when unknown.
+type Location struct {
+	// Country is the ISO 3166-1 alpha-2 country code, e.g. "US".
+	Country string
+	// Region is the ISO 3166-2 code of the largest subdivision, e.g. "US-CA".
+	Region string
+}
+
+// record holds the fields read from MaxMind GeoIP2/GeoLite2 Country and City databases.
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"subdivisions"`
+}
+
+// Resolver looks up IP addresses in a MaxMind-format database. A nil Resolver resolves nothing.
+type Resolver struct {
+	reader *maxminddb.Reader
+	logger *slog.Logger
+}
+
+// Open opens the .mmdb file at path.
+func Open(path string, logger *slog.Logger) (*Resolver, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open geoip database: %w", err)
+	}
+
+	logger.Info("geoip database loaded",
+		slog.String("path", path),
+		slog.String("type", reader.Metadata.DatabaseType),
+	)
+
+	return &Resolver{
+		reader: reader,
+		logger: logger,
+	}, nil
+}
+
+// Lookup returns the location of ip. Unparseable addresses and failed lookups yield an empty Location.
+func (r *Resolver) Lookup(ip string) Location {
+	if r == nil {
+		return Location{}
+	}
+
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return Location{}
+	}
+
+	var rec record
+	if err := r.reader.Lookup(addr, &rec); err != nil {
+		r.logger.Warn("geoip lookup failed",
+			slog.String("ip", ip),
+			slog.String("error", err.Error()),
+		)
+		return Location{}
+	}
+
+	location := Location{Country: rec.Country.ISOCode}
+	if location.Country != "" && len(rec.Subdivisions) > 0 && rec.Subdivisions[0].ISOCode != "" {
+		location.Region = location.Country + "-" + rec.Subdivisions[0].ISOCode
+	}
+
+	return location
+}
+
+// Close releases the database.
+func (r *Resolver) Close() error {
+	if r == nil {
+		return nil
+	}
+	return r.reader.Close()
+}
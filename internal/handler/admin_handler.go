@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/edson-mazvila/url-shortener/internal/repository"
+	"github.com/edson-mazvila/url-shortener/internal/service"
+)
+
+// CacheStatsProvider exposes cache hit and miss counters.
+type CacheStatsProvider interface {
+	Stats() repository.CacheStats
+}
+
+// AdminHandler handles operational endpoints.
+type AdminHandler struct {
+	cache   CacheStatsProvider
+	service *service.URLService
+	logger  *slog.Logger
+}
+
+// NewAdminHandler creates a new admin handler. cache may be nil when caching is disabled.
+func NewAdminHandler(cache CacheStatsProvider, service *service.URLService, logger *slog.Logger) *AdminHandler {
+	return &AdminHandler{
+		cache:   cache,
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CacheStats handles GET /api/admin/cache
+func (h *AdminHandler) CacheStats(w http.ResponseWriter, r *http.Request) {
+	stats := repository.CacheStats{}
+	if h.cache != nil {
+		stats = h.cache.Stats()
+	}
+
+	h.respondJSON(w, http.StatusOK, stats)
+}
+
+// KeyspaceStats handles GET /api/admin/keyspace
+func (h *AdminHandler) KeyspaceStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.service.KeyspaceStats(r.Context())
+	if err != nil {
+		h.logger.Error("failed to get keyspace stats", slog.String("error", err.Error()))
+		h.respondJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "internal server error"})
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, stats)
+}
+
+func (h *AdminHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("failed to encode admin response", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/edson-mazvila/url-shortener/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// APIKeyHeader is the request header that carries an API key for clients that cannot send an
+// Authorization header.
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyContextKey is the request context key of the authenticated API key.
+type apiKeyContextKey struct{}
+
+// APIKeyHandler handles HTTP requests for API keys and authenticates management API requests.
+type APIKeyHandler struct {
+	service *service.APIKeyService
+	logger  *slog.Logger
+}
+
+// NewAPIKeyHandler creates a new API key handler.
+func NewAPIKeyHandler(service *service.APIKeyService, logger *slog.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CreateAPIKeyRequest represents the request body for creating an API key.
+type CreateAPIKeyRequest struct {
+	Name  string `json:"name"`
+	Admin bool   `json:"admin,omitempty"`
+}
+
+// CreateAPIKeyResponse represents the response for creating an API key. Key is only ever returned here.
+type CreateAPIKeyResponse struct {
+	*domain.APIKey
+	Key string `json:"key"`
+}
+
+// UpdateAPIKeyRequest represents the request body for renaming an API key.
+type UpdateAPIKeyRequest struct {
+	Name string `json:"name"`
+}
+
+// ListAPIKeysResponse represents the response for listing API keys.
+type ListAPIKeysResponse struct {
+	Keys []*domain.APIKey `json:"keys"`
+}
+
+// Authenticate rejects requests without a valid API key, sent as a bearer token or in the X-API-Key
+// header, and makes the key available to the handlers behind it.
+func (h *APIKeyHandler) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret := requestAPIKeySecret(r)
+		if secret == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+			h.respondError(w, http.StatusUnauthorized, "api key required",
+				"send an API key as Authorization: Bearer <key> or in the "+APIKeyHeader+" header")
+			return
+		}
+
+		key, err := h.service.Authenticate(r.Context(), secret)
+		if err != nil {
+			if errors.Is(err, domain.ErrInvalidAPIKey) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+			}
+			h.handleServiceError(w, err, "failed to authenticate api key")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKeyContextKey{}, key)))
+	})
+}
+
+// RequireAdmin rejects requests authenticated with a key that is not an admin key. It must run behind
+// Authenticate.
+func (h *APIKeyHandler) RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := requestAPIKey(r); key == nil || !key.Admin {
+			h.respondError(w, http.StatusForbidden, "admin api key required", "")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CreateKey handles POST /api/keys
+func (h *APIKeyHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("invalid request body", slog.String("error", err.Error()))
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	key, secret, err := h.service.CreateKey(r.Context(), req.Name, req.Admin, requestActor(r))
+	if err != nil {
+		h.handleServiceError(w, err, "failed to create api key")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.respondJSON(w, http.StatusCreated, CreateAPIKeyResponse{APIKey: key, Key: secret})
+}
+
+// ListKeys handles GET /api/keys
+func (h *APIKeyHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.ListKeys(r.Context())
+	if err != nil {
+		h.handleServiceError(w, err, "failed to list api keys")
+		return
+	}
+
+	if keys == nil {
+		keys = []*domain.APIKey{}
+	}
+
+	h.respondJSON(w, http.StatusOK, ListAPIKeysResponse{Keys: keys})
+}
+
+// GetKey handles GET /api/keys/{id}
+func (h *APIKeyHandler) GetKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.respondError(w, http.StatusNotFound, "api key not found", "")
+		return
+	}
+
+	key, err := h.service.GetKey(r.Context(), id)
+	if err != nil {
+		h.handleServiceError(w, err, "failed to get api key")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, key)
+}
+
+// UpdateKey handles PATCH /api/keys/{id}
+func (h *APIKeyHandler) UpdateKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.respondError(w, http.StatusNotFound, "api key not found", "")
+		return
+	}
+
+	var req UpdateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("invalid request body", slog.String("error", err.Error()))
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	key, err := h.service.RenameKey(r.Context(), id, req.Name)
+	if err != nil {
+		h.handleServiceError(w, err, "failed to update api key")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, key)
+}
+
+// RevokeKey handles DELETE /api/keys/{id}
+func (h *APIKeyHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.respondError(w, http.StatusNotFound, "api key not found", "")
+		return
+	}
+
+	if err := h.service.RevokeKey(r.Context(), id, requestActor(r)); err != nil {
+		h.handleServiceError(w, err, "failed to revoke api key")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// requestAPIKeySecret returns the API key sent with r, or an empty string if there is none.
+func requestAPIKeySecret(r *http.Request) string {
+	if scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return strings.TrimSpace(r.Header.Get(APIKeyHeader))
+}
+
+// requestAPIKey returns the API key that authenticated r, or nil outside the management API.
+func requestAPIKey(r *http.Request) *domain.APIKey {
+	key, _ := r.Context().Value(apiKeyContextKey{}).(*domain.APIKey)
+	return key
+}
+
+func (h *APIKeyHandler) handleServiceError(w http.ResponseWriter, err error, logMsg string) {
+	if errors.Is(err, domain.ErrInvalidAPIKey) {
+		h.respondError(w, http.StatusUnauthorized, "invalid api key", "")
+		return
+	}
+
+	if errors.Is(err, domain.ErrAPIKeyNotFound) {
+		h.respondError(w, http.StatusNotFound, "api key not found", "")
+		return
+	}
+
+	if errors.Is(err, domain.ErrInvalidAPIKeyName) {
+		h.respondError(w, http.StatusBadRequest, "invalid api key name", err.Error())
+		return
+	}
+
+	h.logger.Error(logMsg, slog.String("error", err.Error()))
+	h.respondError(w, http.StatusInternalServerError, "internal server error", "")
+}
+
+func (h *APIKeyHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("failed to encode response", slog.String("error", err.Error()))
+	}
+}
+
+func (h *APIKeyHandler) respondError(w http.ResponseWriter, status int, error, message string) {
+	h.respondJSON(w, status, ErrorResponse{
+		Error:   error,
+		Message: message,
+	})
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/edson-mazvila/url-shortener/internal/config"
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/edson-mazvila/url-shortener/internal/repository"
+	"github.com/edson-mazvila/url-shortener/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+const testBootstrapKey = "bootstrap-secret-0123456789abcdef"
+
+// newTestKeyRouter serves the API key routes the way NewRouter does, backed by an in-memory store.
+func newTestKeyRouter() http.Handler {
+	logger := slog.New(slog.DiscardHandler)
+	keys := service.NewAPIKeyService(repository.NewMemoryAPIKeyRepository(logger), &config.AuthConfig{BootstrapKey: testBootstrapKey}, logger)
+	h := NewAPIKeyHandler(keys, logger)
+
+	r := chi.NewRouter()
+	r.Route("/api", func(r chi.Router) {
+		r.Use(h.Authenticate)
+		r.Get("/whoami", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(requestActor(r)))
+		})
+		r.Route("/keys", func(r chi.Router) {
+			r.Use(h.RequireAdmin)
+			r.Post("/", h.CreateKey)
+			r.Delete("/{id}", h.RevokeKey)
+		})
+	})
+
+	return r
+}
+
+func TestAPIKeyAuthenticationAndRevocation(t *testing.T) {
+	router := newTestKeyRouter()
+
+	do := func(method, path, body string, header http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		for name, values := range header {
+			for _, value := range values {
+				r.Header.Add(name, value)
+			}
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+	bearer := func(secret string) http.Header {
+		return http.Header{"Authorization": {"Bearer " + secret}}
+	}
+
+	created := do(http.MethodPost, "/api/keys/", `{"name":"ci"}`, http.Header{
+		APIKeyHeader: {testBootstrapKey},
+		"X-Actor":    {"alice"},
+	})
+	if created.Code != http.StatusCreated {
+		t.Fatalf("create key: status %d: %s", created.Code, created.Body)
+	}
+	var issued CreateAPIKeyResponse
+	if err := json.Unmarshal(created.Body.Bytes(), &issued); err != nil {
+		t.Fatalf("decode created key: %v", err)
+	}
+	if issued.CreatedBy != "bootstrap (X-Actor: alice)" {
+		t.Errorf("created_by = %q, want the bootstrap key with the X-Actor note", issued.CreatedBy)
+	}
+
+	steps := []struct {
+		name       string
+		method     string
+		path       string
+		header     http.Header
+		wantStatus int
+		wantBody   string
+		wantAuth   string
+	}{
+		{name: "no key", method: http.MethodGet, path: "/api/whoami", wantStatus: http.StatusUnauthorized, wantAuth: `Bearer realm="api"`},
+		{name: "unknown key", method: http.MethodGet, path: "/api/whoami", header: bearer("usk_wrong"), wantStatus: http.StatusUnauthorized, wantAuth: `Bearer realm="api", error="invalid_token"`},
+		{name: "basic auth", method: http.MethodGet, path: "/api/whoami", header: http.Header{"Authorization": {"Basic " + issued.Key}}, wantStatus: http.StatusUnauthorized},
+		{name: "bearer key", method: http.MethodGet, path: "/api/whoami", header: bearer(issued.Key), wantStatus: http.StatusOK, wantBody: "ci"},
+		{name: "lower-case scheme", method: http.MethodGet, path: "/api/whoami", header: http.Header{"Authorization": {"bearer " + issued.Key}}, wantStatus: http.StatusOK, wantBody: "ci"},
+		{name: "header key", method: http.MethodGet, path: "/api/whoami", header: http.Header{APIKeyHeader: {issued.Key}}, wantStatus: http.StatusOK, wantBody: "ci"},
+		{name: "x-actor cannot impersonate", method: http.MethodGet, path: "/api/whoami", header: http.Header{APIKeyHeader: {issued.Key}, "X-Actor": {"bootstrap"}}, wantStatus: http.StatusOK, wantBody: "ci (X-Actor: bootstrap)"},
+		{name: "non-admin key on admin route", method: http.MethodDelete, path: "/api/keys/" + strconv.FormatInt(issued.ID, 10), header: bearer(issued.Key), wantStatus: http.StatusForbidden},
+		{name: "revoke", method: http.MethodDelete, path: "/api/keys/" + strconv.FormatInt(issued.ID, 10), header: bearer(testBootstrapKey), wantStatus: http.StatusNoContent},
+		{name: "revoked key", method: http.MethodGet, path: "/api/whoami", header: bearer(issued.Key), wantStatus: http.StatusUnauthorized, wantAuth: `Bearer realm="api", error="invalid_token"`},
+		{name: "revoke unknown key", method: http.MethodDelete, path: "/api/keys/999", header: bearer(testBootstrapKey), wantStatus: http.StatusNotFound},
+	}
+
+	for _, step := range steps {
+		w := do(step.method, step.path, "", step.header)
+		if w.Code != step.wantStatus {
+			t.Errorf("%s: status %d, want %d: %s", step.name, w.Code, step.wantStatus, w.Body)
+			continue
+		}
+		if step.wantBody != "" && w.Body.String() != step.wantBody {
+			t.Errorf("%s: body %q, want %q", step.name, w.Body, step.wantBody)
+		}
+		if step.wantAuth != "" && w.Header().Get("WWW-Authenticate") != step.wantAuth {
+			t.Errorf("%s: WWW-Authenticate %q, want %q", step.name, w.Header().Get("WWW-Authenticate"), step.wantAuth)
+		}
+	}
+}
+
+func TestRequestActor(t *testing.T) {
+	key := &domain.APIKey{ID: 3, Name: "deploy-bot"}
+
+	tests := []struct {
+		name    string
+		key     *domain.APIKey
+		xActor  string
+		want    string
+		wantLen int
+	}{
+		{name: "client without a key", want: "192.0.2.1"},
+		{name: "client without a key claiming an actor", xActor: "alice", want: "192.0.2.1 (X-Actor: alice)"},
+		{name: "key", key: key, want: "deploy-bot"},
+		{name: "key with a note", key: key, xActor: "  alice ", want: "deploy-bot (X-Actor: alice)"},
+		{name: "note repeating the key name", key: key, xActor: "deploy-bot", want: "deploy-bot"},
+		{name: "long multi-byte note", key: key, xActor: strings.Repeat("é", 300), wantLen: maxActorLength},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/urls", nil)
+			r.RemoteAddr = "192.0.2.1:54321"
+			if tt.xActor != "" {
+				r.Header.Set("X-Actor", tt.xActor)
+			}
+			if tt.key != nil {
+				r = r.WithContext(context.WithValue(r.Context(), apiKeyContextKey{}, tt.key))
+			}
+
+			got := requestActor(r)
+			if !utf8.ValidString(got) {
+				t.Fatalf("requestActor returned invalid UTF-8: %q", got)
+			}
+			if tt.want != "" && got != tt.want {
+				t.Errorf("requestActor = %q, want %q", got, tt.want)
+			}
+			if tt.wantLen != 0 {
+				if n := utf8.RuneCountInString(got); n != tt.wantLen {
+					t.Errorf("requestActor has %d characters, want %d", n, tt.wantLen)
+				}
+				if !strings.HasPrefix(got, key.Name+" (X-Actor: ") {
+					t.Errorf("requestActor = %q, want it to start with the key name", got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/edson-mazvila/url-shortener/internal/pages"
+)
+
+// handleVisitError responds to a failed visit of a short link: with an HTML error page for clients
+// that prefer HTML, such as browsers, and like handleServiceError for everyone else.
+func (h *URLHandler) handleVisitError(w http.ResponseWriter, r *http.Request, shortCode string, err error) {
+	w.Header().Add("Vary", "Accept")
+
+	if !acceptsHTML(r) {
+		h.handleServiceError(w, err, "failed to get original url")
+		return
+	}
+
+	data := pages.ErrorData{ShortCode: shortCode}
+	switch {
+	case errors.Is(err, domain.ErrURLNotFound):
+		data.Status, data.Title = http.StatusNotFound, "Link not found"
+		data.Message = "This link does not exist. Check that it was copied correctly."
+	case errors.Is(err, domain.ErrURLDisabled):
+		data.Status, data.Title = http.StatusForbidden, "Link disabled"
+		data.Message = "This link has been disabled."
+	case errors.Is(err, domain.ErrURLExpired):
+		data.Status, data.Title = http.StatusGone, "Link expired"
+		data.Message = "This link has expired."
+	case errors.Is(err, domain.ErrClickLimitReached):
+		data.Status, data.Title = http.StatusGone, "Link no longer available"
+		data.Message = "This link has reached its maximum number of visits."
+	case errors.Is(err, domain.ErrURLNotActive):
+		data.Status, data.Title = h.service.NotLiveStatus(), "Link not live yet"
+		data.Message = "This link is not live yet. Try again later."
+	default:
+		h.logger.Error("failed to get original url", slog.String("error", err.Error()))
+		data.Status, data.Title = http.StatusInternalServerError, "Something went wrong"
+		data.Message = "Try again later."
+	}
+
+	var page bytes.Buffer
+	if renderErr := h.pages.Render(&page, h.pages.ErrorPage(data.Status), data); renderErr != nil {
+		h.logger.Error("failed to render error page",
+			slog.Int("status", data.Status),
+			slog.String("error", renderErr.Error()),
+		)
+		h.handleServiceError(w, err, "failed to get original url")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.WriteHeader(data.Status)
+
+	if _, err := page.WriteTo(w); err != nil {
+		h.logger.Error("failed to write error page", slog.String("error", err.Error()))
+	}
+}
+
+// acceptsHTML reports whether the Accept header of r ranks HTML at least as high as JSON, as browsers
+// do. Wildcards count for neither, so clients that accept anything get JSON.
+func acceptsHTML(r *http.Request) bool {
+	var htmlQ, jsonQ float64
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+
+		switch mediaType {
+		case "text/html", "application/xhtml+xml":
+			htmlQ = max(htmlQ, q)
+		case "application/json":
+			jsonQ = max(jsonQ, q)
+		}
+	}
+
+	return htmlQ > 0 && htmlQ >= jsonQ
+}
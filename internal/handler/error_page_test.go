@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAcceptsHTML(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		want   bool
+	}{
+		{name: "no header", accept: "", want: false},
+		{name: "anything", accept: "*/*", want: false},
+		{name: "text wildcard", accept: "text/*", want: false},
+		{name: "json", accept: "application/json", want: false},
+		{name: "html", accept: "text/html", want: true},
+		{name: "xhtml", accept: "application/xhtml+xml", want: true},
+		{
+			name:   "browser",
+			accept: "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8",
+			want:   true,
+		},
+		{name: "json preferred", accept: "text/html;q=0.5, application/json", want: false},
+		{name: "html preferred", accept: "application/json;q=0.5, text/html", want: true},
+		{name: "tie goes to html", accept: "application/json;q=0.8, text/html;q=0.8", want: true},
+		{name: "html refused", accept: "text/html;q=0", want: false},
+		{name: "invalid quality is ignored", accept: "text/html;q=high, application/json", want: false},
+		{name: "malformed entry is ignored", accept: "/;;, text/html", want: true},
+		{name: "case insensitive media type", accept: "Text/HTML", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/abc1234", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+
+			if got := acceptsHTML(r); got != tt.want {
+				t.Errorf("acceptsHTML(%q) = %v, want %v", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header that makes a POST safe to retry.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed from a stored idempotency record.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotentBodySize = 1 << 20
+)
+
+// Idempotency stores the successful response of requests carrying an Idempotency-Key header and
+// replays it for repeated requests with the same key and body. Failed requests release the key. Keys
+// are scoped to the authenticated API key, so two clients may use the same key independently.
+func (h *URLHandler) Idempotency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodySize))
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		var apiKeyID int64
+		if apiKey := requestAPIKey(r); apiKey != nil {
+			apiKeyID = apiKey.ID
+		}
+
+		hash := sha256.New()
+		io.WriteString(hash, r.Method+" "+r.URL.Path+"\n")
+		hash.Write(body)
+
+		record, err := h.idempotency.Begin(r.Context(), apiKeyID, key, hex.EncodeToString(hash.Sum(nil)))
+		if err != nil {
+			h.handleServiceError(w, err, "failed to check idempotency key")
+			return
+		}
+
+		if record != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set(IdempotentReplayedHeader, "true")
+			w.WriteHeader(record.StatusCode)
+			w.Write(record.ResponseBody)
+			return
+		}
+
+		var response bytes.Buffer
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		ww.Tee(&response)
+
+		next.ServeHTTP(ww, r)
+
+		// The response has been sent, so the outcome is stored even if the client went away.
+		ctx := context.WithoutCancel(r.Context())
+
+		if status := ww.Status(); status >= 200 && status < 300 {
+			if err := h.idempotency.Complete(ctx, apiKeyID, key, status, response.Bytes()); err != nil {
+				h.logger.Error("failed to store idempotent response",
+					slog.String("idempotency_key", key),
+					slog.String("error", err.Error()),
+				)
+			}
+			return
+		}
+
+		if err := h.idempotency.Release(ctx, apiKeyID, key); err != nil {
+			h.logger.Error("failed to release idempotency key",
+				slog.String("idempotency_key", key),
+				slog.String("error", err.Error()),
+			)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+)
+
+// maxPasswordFormSize bounds the body of a password form submission.
+const maxPasswordFormSize = 4 << 10
+
+var passwordFormTemplate = template.Must(template.New("password").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Password required</title>
+</head>
+<body>
+<form method="post">
+<p>This link is protected. Enter the password to continue.</p>
+{{if .Message}}<p role="alert">{{.Message}}</p>{{end}}
+<input type="password" name="password" autocomplete="current-password" autofocus required>
+<button type="submit">Continue</button>
+</form>
+</body>
+</html>
+`))
+
+// passwordFormStatus returns the status to serve the password form with for err, or false when err
+// is not about a link password.
+func passwordFormStatus(err error) (int, string, bool) {
+	switch {
+	case errors.Is(err, domain.ErrPasswordRequired):
+		return http.StatusOK, "", true
+	case errors.Is(err, domain.ErrIncorrectPassword):
+		return http.StatusForbidden, "Incorrect password.", true
+	case errors.Is(err, domain.ErrTooManyPasswordAttempts):
+		return http.StatusTooManyRequests, "Too many incorrect passwords. Try again later.", true
+	default:
+		return 0, "", false
+	}
+}
+
+// respondPasswordForm serves the form asking for the password of a protected link. It posts back to
+// the requested URL, so the path and query of the visit are kept.
+func (h *URLHandler) respondPasswordForm(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.WriteHeader(status)
+
+	if err := passwordFormTemplate.Execute(w, struct{ Message string }{message}); err != nil {
+		h.logger.Error("failed to render password form", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/edson-mazvila/url-shortener/internal/pages"
+	"github.com/edson-mazvila/url-shortener/internal/service"
+)
+
+// respondPreview serves the preview page of a link. Its continue form posts to the link itself, with
+// the path and query of the visit, so continuing is counted like a direct visit.
+func (h *URLHandler) respondPreview(w http.ResponseWriter, r *http.Request, shortCode, path string, redirect *service.Redirect) {
+	continueURL := h.service.GetFullURL(shortCode)
+	if path != "" {
+		continueURL += "/" + path
+	}
+	if r.URL.RawQuery != "" {
+		continueURL += "?" + r.URL.RawQuery
+	}
+
+	data := pages.PreviewData{
+		ShortCode:   shortCode,
+		ShortURL:    h.service.GetFullURL(shortCode),
+		Destination: redirect.Location,
+		CreatedAt:   redirect.URL.CreatedAt,
+		ContinueURL: continueURL,
+		Variant:     redirect.Variant,
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, no-store")
+	if redirect.Personalized {
+		w.Header().Set("Vary", "User-Agent")
+	}
+
+	if err := h.pages.Render(w, pages.Preview, data); err != nil {
+		h.logger.Error("failed to render preview page",
+			slog.String("short_code", shortCode),
+			slog.String("error", err.Error()),
+		)
+		h.respondError(w, http.StatusInternalServerError, "internal server error", "")
+	}
+}
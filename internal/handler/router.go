@@ -10,7 +10,7 @@ import (
 )
 
 // Router creates and configures the HTTP router.
-func NewRouter(urlHandler *URLHandler, healthHandler *HealthHandler, logger *slog.Logger) http.Handler {
+func NewRouter(urlHandler *URLHandler, healthHandler *HealthHandler, utmTemplateHandler *UTMTemplateHandler, scheduleHandler *ScheduleHandler, apiKeyHandler *APIKeyHandler, adminHandler *AdminHandler, logger *slog.Logger) http.Handler {
 	r := chi.NewRouter()
 
 	r.Use(middleware.RequestID)
@@ -21,16 +21,57 @@ func NewRouter(urlHandler *URLHandler, healthHandler *HealthHandler, logger *slo
 
 	r.Get("/health", healthHandler.Health)
 
+	// Everything under /api requires an API key; health checks and the visitor routes stay public.
 	r.Route("/api", func(r chi.Router) {
+		r.Use(apiKeyHandler.Authenticate)
+
 		r.Route("/urls", func(r chi.Router) {
-			r.Post("/", urlHandler.CreateShortURL)
+			r.With(urlHandler.Idempotency).Post("/", urlHandler.CreateShortURL)
 			r.Get("/", urlHandler.ListURLs)
 			r.Get("/{shortCode}", urlHandler.GetURLMetadata)
+			r.Patch("/{shortCode}", urlHandler.UpdateURL)
+			r.Get("/{shortCode}/stats", urlHandler.GetURLStats)
+			r.Get("/{shortCode}/history", urlHandler.GetURLHistory)
+			r.Post("/{shortCode}/rollback", urlHandler.RollbackURL)
+			r.Post("/{shortCode}/schedules", scheduleHandler.CreateSchedule)
+			r.Get("/{shortCode}/schedules", scheduleHandler.ListSchedules)
+			r.Delete("/{shortCode}/schedules/{id}", scheduleHandler.DeleteSchedule)
+			r.Post("/{shortCode}/disable", urlHandler.DisableURL)
+			r.Post("/{shortCode}/enable", urlHandler.EnableURL)
+			r.Post("/{shortCode}/restore", urlHandler.RestoreURL)
 			r.Delete("/{shortCode}", urlHandler.DeleteURL)
 		})
+
+		r.Route("/utm-templates", func(r chi.Router) {
+			r.Post("/", utmTemplateHandler.CreateTemplate)
+			r.Get("/", utmTemplateHandler.ListTemplates)
+			r.Get("/{name}", utmTemplateHandler.GetTemplate)
+			r.Put("/{name}", utmTemplateHandler.UpdateTemplate)
+			r.Delete("/{name}", utmTemplateHandler.DeleteTemplate)
+		})
+
+		r.Route("/keys", func(r chi.Router) {
+			r.Use(apiKeyHandler.RequireAdmin)
+			r.Post("/", apiKeyHandler.CreateKey)
+			r.Get("/", apiKeyHandler.ListKeys)
+			r.Get("/{id}", apiKeyHandler.GetKey)
+			r.Patch("/{id}", apiKeyHandler.UpdateKey)
+			r.Delete("/{id}", apiKeyHandler.RevokeKey)
+		})
+
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(apiKeyHandler.RequireAdmin)
+			r.Get("/cache", adminHandler.CacheStats)
+			r.Get("/keyspace", adminHandler.KeyspaceStats)
+		})
 	})
 
+	r.Get("/{shortCode}+", urlHandler.PreviewURL)
+	r.Post("/{shortCode}+", urlHandler.PreviewURL)
 	r.Get("/{shortCode}", urlHandler.RedirectToOriginal)
+	r.Get("/{shortCode}/*", urlHandler.RedirectToOriginal)
+	r.Post("/{shortCode}", urlHandler.RedirectToOriginal)
+	r.Post("/{shortCode}/*", urlHandler.RedirectToOriginal)
 
 	return r
 }
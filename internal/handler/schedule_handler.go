@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/edson-mazvila/url-shortener/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// ScheduleHandler handles HTTP requests for scheduled URL changes.
+type ScheduleHandler struct {
+	service *service.ScheduleService
+	logger  *slog.Logger
+}
+
+// NewScheduleHandler creates a new schedule handler.
+func NewScheduleHandler(service *service.ScheduleService, logger *slog.Logger) *ScheduleHandler {
+	return &ScheduleHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ScheduleChangeRequest represents the request body for scheduling a destination change.
+type ScheduleChangeRequest struct {
+	URL     string    `json:"url"`
+	ApplyAt time.Time `json:"apply_at"`
+}
+
+// ListSchedulesResponse represents the response for listing the scheduled changes of a URL.
+type ListSchedulesResponse struct {
+	Schedules []*domain.ScheduledChange `json:"schedules"`
+}
+
+// CreateSchedule handles POST /api/urls/{shortCode}/schedules
+func (h *ScheduleHandler) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	var req ScheduleChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("invalid request body", slog.String("error", err.Error()))
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if req.URL == "" || req.ApplyAt.IsZero() {
+		h.respondError(w, http.StatusBadRequest, "url and apply_at are required", "")
+		return
+	}
+
+	schedule, err := h.service.ScheduleChange(r.Context(), chi.URLParam(r, "shortCode"), req.URL, req.ApplyAt, requestActor(r))
+	if err != nil {
+		h.handleServiceError(w, err, "failed to schedule url change")
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, schedule)
+}
+
+// ListSchedules handles GET /api/urls/{shortCode}/schedules
+func (h *ScheduleHandler) ListSchedules(w http.ResponseWriter, r *http.Request) {
+	schedules, err := h.service.ListChanges(r.Context(), chi.URLParam(r, "shortCode"))
+	if err != nil {
+		h.handleServiceError(w, err, "failed to list scheduled url changes")
+		return
+	}
+
+	if schedules == nil {
+		schedules = []*domain.ScheduledChange{}
+	}
+
+	h.respondJSON(w, http.StatusOK, ListSchedulesResponse{Schedules: schedules})
+}
+
+// DeleteSchedule handles DELETE /api/urls/{shortCode}/schedules/{id}
+func (h *ScheduleHandler) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.respondError(w, http.StatusNotFound, "scheduled change not found", "")
+		return
+	}
+
+	if err := h.service.CancelChange(r.Context(), chi.URLParam(r, "shortCode"), id); err != nil {
+		h.handleServiceError(w, err, "failed to cancel scheduled url change")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ScheduleHandler) handleServiceError(w http.ResponseWriter, err error, logMsg string) {
+	if errors.Is(err, domain.ErrURLNotFound) {
+		h.respondError(w, http.StatusNotFound, "url not found", "")
+		return
+	}
+
+	if errors.Is(err, domain.ErrScheduleNotFound) {
+		h.respondError(w, http.StatusNotFound, "scheduled change not found", "")
+		return
+	}
+
+	if errors.Is(err, domain.ErrInvalidURL) {
+		h.respondError(w, http.StatusBadRequest, "invalid url", "")
+		return
+	}
+
+	if errors.Is(err, domain.ErrInvalidSchedule) {
+		h.respondError(w, http.StatusBadRequest, "invalid scheduled change", err.Error())
+		return
+	}
+
+	h.logger.Error(logMsg, slog.String("error", err.Error()))
+	h.respondError(w, http.StatusInternalServerError, "internal server error", "")
+}
+
+func (h *ScheduleHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("failed to encode response", slog.String("error", err.Error()))
+	}
+}
+
+func (h *ScheduleHandler) respondError(w http.ResponseWriter, status int, error, message string) {
+	h.respondJSON(w, status, ErrorResponse{
+		Error:   error,
+		Message: message,
+	})
+}
@@ -1,47 +1,139 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/edson-mazvila/url-shortener/internal/pages"
 	"github.com/edson-mazvila/url-shortener/internal/service"
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 )
 
 // URLHandler handles HTTP requests for URL operations.
 type URLHandler struct {
-	service *service.URLService
-	logger  *slog.Logger
+	service     *service.URLService
+	stats       *service.StatsService
+	idempotency *service.IdempotencyService
+	pages       *pages.Renderer
+	logger      *slog.Logger
 }
 
 // NewURLHandler creates a new URL handler.
-func NewURLHandler(service *service.URLService, logger *slog.Logger) *URLHandler {
+func NewURLHandler(service *service.URLService, stats *service.StatsService, idempotency *service.IdempotencyService, pages *pages.Renderer, logger *slog.Logger) *URLHandler {
 	return &URLHandler{
-		service: service,
-		logger:  logger,
+		service:     service,
+		stats:       stats,
+		idempotency: idempotency,
+		pages:       pages,
+		logger:      logger,
 	}
 }
 
 // CreateShortURLRequest represents the request body for creating a short URL.
 type CreateShortURLRequest struct {
-	URL        string `json:"url"`
-	CustomCode string `json:"custom_code,omitempty"`
-	TTL        int64  `json:"ttl,omitempty"`
+	URL              string                 `json:"url"`
+	CustomCode       string                 `json:"custom_code,omitempty"`
+	TTL              int64                  `json:"ttl,omitempty"`
+	Dedupe           bool                   `json:"dedupe,omitempty"`
+	RedirectType     int                    `json:"redirect_type,omitempty"`
+	QueryPassthrough bool                   `json:"query_passthrough,omitempty"`
+	QueryPrecedence  string                 `json:"query_precedence,omitempty"`
+	PathPassthrough  bool                   `json:"path_passthrough,omitempty"`
+	UTMTemplate      string                 `json:"utm_template,omitempty"`
+	UTM              domain.UTMParams       `json:"utm,omitempty"`
+	TargetingRules   []domain.TargetingRule `json:"targeting_rules,omitempty"`
+	Variants         []domain.Variant       `json:"variants,omitempty"`
+	StickyVariants   bool                   `json:"sticky_variants,omitempty"`
+	Password         string                 `json:"password,omitempty"`
+	MaxClicks        int64                  `json:"max_clicks,omitempty"`
+	ActivatesAt      *time.Time             `json:"activates_at,omitempty"`
+	AlwaysPreview    bool                   `json:"always_preview,omitempty"`
+	FallbackURL      string                 `json:"fallback_url,omitempty"`
 }
 
 // CreateShortURLResponse represents the response for creating a short URL.
 type CreateShortURLResponse struct {
-	ID          int64      `json:"id"`
-	ShortCode   string     `json:"short_code"`
-	ShortURL    string     `json:"short_url"`
-	OriginalURL string     `json:"original_url"`
-	CreatedAt   time.Time  `json:"created_at"`
-	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	ID                int64                  `json:"id"`
+	ShortCode         string                 `json:"short_code"`
+	ShortURL          string                 `json:"short_url"`
+	OriginalURL       string                 `json:"original_url"`
+	CreatedAt         time.Time              `json:"created_at"`
+	ExpiresAt         *time.Time             `json:"expires_at,omitempty"`
+	RedirectType      int                    `json:"redirect_type,omitempty"`
+	QueryPassthrough  bool                   `json:"query_passthrough,omitempty"`
+	QueryPrecedence   string                 `json:"query_precedence,omitempty"`
+	PathPassthrough   bool                   `json:"path_passthrough,omitempty"`
+	BaseURL           string                 `json:"base_url,omitempty"`
+	UTM               *domain.UTMParams      `json:"utm,omitempty"`
+	TargetingRules    []domain.TargetingRule `json:"targeting_rules,omitempty"`
+	Variants          []domain.Variant       `json:"variants,omitempty"`
+	StickyVariants    bool                   `json:"sticky_variants,omitempty"`
+	PasswordProtected bool                   `json:"password_protected,omitempty"`
+	MaxClicks         *int64                 `json:"max_clicks,omitempty"`
+	RemainingClicks   *int64                 `json:"remaining_clicks,omitempty"`
+	ActivatesAt       *time.Time             `json:"activates_at,omitempty"`
+	AlwaysPreview     bool                   `json:"always_preview,omitempty"`
+	FallbackURL       string                 `json:"fallback_url,omitempty"`
+}
+
+// UpdateURLRequest represents the request body for editing a short URL. Omitted fields are left unchanged.
+type UpdateURLRequest struct {
+	URL              *string                 `json:"url,omitempty"`
+	ExpiresAt        nullableTime            `json:"expires_at"`
+	TTL              int64                   `json:"ttl,omitempty"`
+	ExtendBy         int64                   `json:"extend_by,omitempty"`
+	RedirectType     *int                    `json:"redirect_type,omitempty"`
+	QueryPassthrough *bool                   `json:"query_passthrough,omitempty"`
+	QueryPrecedence  *string                 `json:"query_precedence,omitempty"`
+	PathPassthrough  *bool                   `json:"path_passthrough,omitempty"`
+	UTMTemplate      *string                 `json:"utm_template,omitempty"`
+	UTM              *domain.UTMParams       `json:"utm,omitempty"`
+	TargetingRules   *[]domain.TargetingRule `json:"targeting_rules,omitempty"`
+	Variants         *[]domain.Variant       `json:"variants,omitempty"`
+	StickyVariants   *bool                   `json:"sticky_variants,omitempty"`
+	Password         *string                 `json:"password,omitempty"`
+	MaxClicks        *int64                  `json:"max_clicks,omitempty"`
+	ActivatesAt      nullableTime            `json:"activates_at"`
+	AlwaysPreview    *bool                   `json:"always_preview,omitempty"`
+	FallbackURL      *string                 `json:"fallback_url,omitempty"`
+}
+
+// nullableTime distinguishes an omitted JSON timestamp from an explicit null.
+type nullableTime struct {
+	Set   bool
+	Value *time.Time
+}
+
+// UnmarshalJSON records that the field was present and parses it unless it is null.
+func (t *nullableTime) UnmarshalJSON(data []byte) error {
+	t.Set = true
+	if string(data) == "null" {
+		t.Value = nil
+		return nil
+	}
+
+	var value time.Time
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	t.Value = &value
+
+	return nil
+}
+
+// RollbackURLRequest represents the request body for restoring a previous version of a short URL.
+type RollbackURLRequest struct {
+	Version int64 `json:"version"`
 }
 
 // ErrorResponse represents an error response.
@@ -79,26 +171,78 @@ func (h *URLHandler) CreateShortURL(w http.ResponseWriter, r *http.Request) {
 		ttl = time.Duration(req.TTL) * time.Second
 	}
 
-	urlEntity, err := h.service.CreateShortURL(ctx, req.URL, req.CustomCode, ttl)
+	urlEntity, created, err := h.service.CreateShortURL(ctx, req.URL, service.CreateURLOptions{
+		CustomCode:       req.CustomCode,
+		TTL:              ttl,
+		Dedupe:           req.Dedupe,
+		RedirectType:     req.RedirectType,
+		QueryPassthrough: req.QueryPassthrough,
+		QueryPrecedence:  req.QueryPrecedence,
+		PathPassthrough:  req.PathPassthrough,
+		UTMTemplate:      req.UTMTemplate,
+		UTM:              req.UTM,
+		TargetingRules:   req.TargetingRules,
+		Variants:         req.Variants,
+		StickyVariants:   req.StickyVariants,
+		Password:         req.Password,
+		MaxClicks:        req.MaxClicks,
+		ActivatesAt:      req.ActivatesAt,
+		AlwaysPreview:    req.AlwaysPreview,
+		FallbackURL:      req.FallbackURL,
+		Actor:            requestActor(r),
+	})
 	if err != nil {
 		h.handleServiceError(w, err, "failed to create short url")
 		return
 	}
 
 	response := CreateShortURLResponse{
-		ID:          urlEntity.ID,
-		ShortCode:   urlEntity.ShortCode,
-		ShortURL:    h.service.GetFullURL(urlEntity.ShortCode),
-		OriginalURL: urlEntity.OriginalURL,
-		CreatedAt:   urlEntity.CreatedAt,
-		ExpiresAt:   urlEntity.ExpiresAt,
+		ID:                urlEntity.ID,
+		ShortCode:         urlEntity.ShortCode,
+		ShortURL:          h.service.GetFullURL(urlEntity.ShortCode),
+		OriginalURL:       urlEntity.OriginalURL,
+		CreatedAt:         urlEntity.CreatedAt,
+		ExpiresAt:         urlEntity.ExpiresAt,
+		RedirectType:      urlEntity.RedirectType,
+		QueryPassthrough:  urlEntity.QueryPassthrough,
+		QueryPrecedence:   urlEntity.QueryPrecedence,
+		PathPassthrough:   urlEntity.PathPassthrough,
+		BaseURL:           urlEntity.BaseURL,
+		UTM:               urlEntity.UTM,
+		TargetingRules:    urlEntity.TargetingRules,
+		Variants:          urlEntity.Variants,
+		StickyVariants:    urlEntity.StickyVariants,
+		PasswordProtected: urlEntity.IsPasswordProtected(),
+		MaxClicks:         urlEntity.MaxClicks,
+		RemainingClicks:   urlEntity.RemainingClicks(),
+		ActivatesAt:       urlEntity.ActivatesAt,
+		AlwaysPreview:     urlEntity.AlwaysPreview,
+		FallbackURL:       urlEntity.FallbackURL,
 	}
 
-	h.respondJSON(w, http.StatusCreated, response)
+	status := http.StatusCreated
+	if !created {
+		status = http.StatusOK
+	}
+
+	h.respondJSON(w, status, response)
 }
 
-// RedirectToOriginal handles GET /{shortCode}
+// RedirectToOriginal handles GET and POST /{shortCode} and /{shortCode}/*. POST submits the password
+// of a protected link, or continues from the preview page.
 func (h *URLHandler) RedirectToOriginal(w http.ResponseWriter, r *http.Request) {
+	h.followLink(w, r, false)
+}
+
+// PreviewURL handles GET and POST /{shortCode}+, which show the preview page of a link instead of
+// redirecting.
+func (h *URLHandler) PreviewURL(w http.ResponseWriter, r *http.Request) {
+	h.followLink(w, r, true)
+}
+
+// followLink redirects the visitor to the destination of the requested link, or shows its preview
+// page when preview is set or the link is always previewed.
+func (h *URLHandler) followLink(w http.ResponseWriter, r *http.Request, preview bool) {
 	ctx := r.Context()
 	shortCode := chi.URLParam(r, "shortCode")
 
@@ -107,18 +251,86 @@ func (h *URLHandler) RedirectToOriginal(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	urlEntity, err := h.service.GetOriginalURL(ctx, shortCode)
+	click := &domain.Click{
+		Referrer:  r.Referer(),
+		UserAgent: r.UserAgent(),
+		IPAddress: clientIP(r),
+		RequestID: truncateRunes(middleware.GetReqID(ctx), maxRequestIDLength),
+	}
+
+	visit := service.Visit{
+		Path:    chi.URLParam(r, "*"),
+		Query:   r.URL.Query(),
+		Preview: preview,
+		Click:   click,
+	}
+	if cookie, err := r.Cookie(variantCookieName(shortCode)); err == nil {
+		visit.Variant = cookie.Value
+	}
+	if r.Method == http.MethodPost {
+		r.Body = http.MaxBytesReader(w, r.Body, maxPasswordFormSize)
+		visit.Password = r.PostFormValue("password")
+		if r.PostFormValue("continue") != "" {
+			visit.Confirmed = true
+			if variant := r.PostFormValue("variant"); variant != "" {
+				visit.Variant = variant
+			}
+		}
+	}
+
+	redirect, err := h.service.ResolveRedirect(ctx, shortCode, visit)
 	if err != nil {
-		h.handleServiceError(w, err, "failed to get original url")
+		if status, message, ok := passwordFormStatus(err); ok {
+			h.respondPasswordForm(w, status, message)
+			return
+		}
+		h.handleVisitError(w, r, shortCode, err)
 		return
 	}
 
+	if redirect.Preview {
+		h.respondPreview(w, r, shortCode, visit.Path, redirect)
+		return
+	}
+
+	// Answer form submissions with 303 so browsers never send the password on to the destination.
+	status := redirect.Status
+	if r.Method == http.MethodPost {
+		status = http.StatusSeeOther
+	}
+
 	h.logger.Debug("redirecting",
 		slog.String("short_code", shortCode),
-		slog.String("original_url", urlEntity.OriginalURL),
+		slog.String("location", redirect.Location),
+		slog.String("target_rule", redirect.TargetRule),
+		slog.String("variant", redirect.Variant),
+		slog.Bool("fallback", redirect.Fallback),
 	)
 
-	http.Redirect(w, r, urlEntity.OriginalURL, http.StatusMovedPermanently)
+	if redirect.StickyFor > 0 {
+		http.SetCookie(w, &http.Cookie{
+			Name:     variantCookieName(shortCode),
+			Value:    redirect.Variant,
+			Path:     "/" + shortCode,
+			MaxAge:   int(redirect.StickyFor / time.Second),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+
+	scope := "public"
+	if redirect.Personalized {
+		scope = "private"
+		w.Header().Set("Vary", "User-Agent")
+	}
+
+	if redirect.CacheMaxAge > 0 {
+		w.Header().Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", scope, int64(redirect.CacheMaxAge/time.Second)))
+	} else {
+		w.Header().Set("Cache-Control", "private, no-store")
+	}
+
+	http.Redirect(w, r, redirect.Location, status)
 }
 
 // GetURLMetadata handles GET /api/urls/{shortCode}
@@ -137,6 +349,208 @@ func (h *URLHandler) GetURLMetadata(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("ETag", formatETag(urlEntity.Version))
+	h.respondJSON(w, http.StatusOK, urlEntity)
+}
+
+// UpdateURL handles PATCH /api/urls/{shortCode}
+func (h *URLHandler) UpdateURL(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	shortCode := chi.URLParam(r, "shortCode")
+
+	if shortCode == "" {
+		h.respondError(w, http.StatusBadRequest, "short code is required", "")
+		return
+	}
+
+	version, ok := h.ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateURLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("invalid request body", slog.String("error", err.Error()))
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	update := service.URLUpdate{
+		OriginalURL:      req.URL,
+		SetExpiry:        req.ExpiresAt.Set,
+		ExpiresAt:        req.ExpiresAt.Value,
+		ExtendBy:         time.Duration(req.ExtendBy) * time.Second,
+		RedirectType:     req.RedirectType,
+		QueryPassthrough: req.QueryPassthrough,
+		QueryPrecedence:  req.QueryPrecedence,
+		PathPassthrough:  req.PathPassthrough,
+		UTMTemplate:      req.UTMTemplate,
+		UTM:              req.UTM,
+		TargetingRules:   req.TargetingRules,
+		Variants:         req.Variants,
+		StickyVariants:   req.StickyVariants,
+		Password:         req.Password,
+		MaxClicks:        req.MaxClicks,
+		SetActivation:    req.ActivatesAt.Set,
+		ActivatesAt:      req.ActivatesAt.Value,
+		AlwaysPreview:    req.AlwaysPreview,
+		FallbackURL:      req.FallbackURL,
+		Actor:            requestActor(r),
+	}
+
+	if req.TTL != 0 {
+		if req.ExpiresAt.Set || req.TTL < 0 {
+			h.respondError(w, http.StatusBadRequest, "invalid expiry", "ttl must be positive and cannot be combined with expires_at")
+			return
+		}
+		expiry := time.Now().Add(time.Duration(req.TTL) * time.Second)
+		update.SetExpiry = true
+		update.ExpiresAt = &expiry
+	}
+
+	if update.OriginalURL == nil && !update.SetExpiry && update.ExtendBy == 0 && update.RedirectType == nil &&
+		update.QueryPassthrough == nil && update.QueryPrecedence == nil && update.PathPassthrough == nil &&
+		update.UTMTemplate == nil && update.UTM == nil && update.TargetingRules == nil &&
+		update.Variants == nil && update.StickyVariants == nil && update.Password == nil &&
+		update.MaxClicks == nil && !update.SetActivation && update.AlwaysPreview == nil &&
+		update.FallbackURL == nil {
+		h.respondError(w, http.StatusBadRequest, "nothing to update", "")
+		return
+	}
+
+	urlEntity, err := h.service.UpdateURL(ctx, shortCode, version, update)
+	if err != nil {
+		h.handleServiceError(w, err, "failed to update url")
+		return
+	}
+
+	w.Header().Set("ETag", formatETag(urlEntity.Version))
+	h.respondJSON(w, http.StatusOK, urlEntity)
+}
+
+// GetURLStats handles GET /api/urls/{shortCode}/stats
+func (h *URLHandler) GetURLStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	shortCode := chi.URLParam(r, "shortCode")
+
+	if shortCode == "" {
+		h.respondError(w, http.StatusBadRequest, "short code is required", "")
+		return
+	}
+
+	query := r.URL.Query()
+
+	from, err := parseTimeParam(query.Get("from"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid from", "expected RFC 3339 timestamp")
+		return
+	}
+
+	to, err := parseTimeParam(query.Get("to"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid to", "expected RFC 3339 timestamp")
+		return
+	}
+
+	interval := domain.StatsInterval(query.Get("interval"))
+
+	stats, err := h.stats.GetClickStats(ctx, shortCode, from, to, interval)
+	if err != nil {
+		h.handleServiceError(w, err, "failed to get url stats")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, stats)
+}
+
+// GetURLHistory handles GET /api/urls/{shortCode}/history
+func (h *URLHandler) GetURLHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	shortCode := chi.URLParam(r, "shortCode")
+
+	if shortCode == "" {
+		h.respondError(w, http.StatusBadRequest, "short code is required", "")
+		return
+	}
+
+	history, err := h.service.GetURLHistory(ctx, shortCode)
+	if err != nil {
+		h.handleServiceError(w, err, "failed to get url history")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, history)
+}
+
+// RollbackURL handles POST /api/urls/{shortCode}/rollback
+func (h *URLHandler) RollbackURL(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	shortCode := chi.URLParam(r, "shortCode")
+
+	if shortCode == "" {
+		h.respondError(w, http.StatusBadRequest, "short code is required", "")
+		return
+	}
+
+	version, ok := h.ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var req RollbackURLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("invalid request body", slog.String("error", err.Error()))
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if req.Version < 1 {
+		h.respondError(w, http.StatusBadRequest, "version is required", "")
+		return
+	}
+
+	urlEntity, err := h.service.RollbackURL(ctx, shortCode, version, req.Version, requestActor(r))
+	if err != nil {
+		h.handleServiceError(w, err, "failed to roll back url")
+		return
+	}
+
+	w.Header().Set("ETag", formatETag(urlEntity.Version))
+	h.respondJSON(w, http.StatusOK, urlEntity)
+}
+
+// DisableURL handles POST /api/urls/{shortCode}/disable
+func (h *URLHandler) DisableURL(w http.ResponseWriter, r *http.Request) {
+	h.changeState(w, r, h.service.DisableURL, "failed to disable url")
+}
+
+// EnableURL handles POST /api/urls/{shortCode}/enable
+func (h *URLHandler) EnableURL(w http.ResponseWriter, r *http.Request) {
+	h.changeState(w, r, h.service.EnableURL, "failed to enable url")
+}
+
+// RestoreURL handles POST /api/urls/{shortCode}/restore
+func (h *URLHandler) RestoreURL(w http.ResponseWriter, r *http.Request) {
+	h.changeState(w, r, h.service.RestoreURL, "failed to restore url")
+}
+
+// changeState applies a state change such as disabling to the URL named in the request and responds
+// with the updated URL.
+func (h *URLHandler) changeState(w http.ResponseWriter, r *http.Request, change func(ctx context.Context, shortCode, actor string) (*domain.URL, error), logMsg string) {
+	shortCode := chi.URLParam(r, "shortCode")
+
+	if shortCode == "" {
+		h.respondError(w, http.StatusBadRequest, "short code is required", "")
+		return
+	}
+
+	urlEntity, err := change(r.Context(), shortCode, requestActor(r))
+	if err != nil {
+		h.handleServiceError(w, err, logMsg)
+		return
+	}
+
+	w.Header().Set("ETag", formatETag(urlEntity.Version))
 	h.respondJSON(w, http.StatusOK, urlEntity)
 }
 
@@ -150,7 +564,7 @@ func (h *URLHandler) DeleteURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.DeleteURL(ctx, shortCode); err != nil {
+	if err := h.service.DeleteURL(ctx, shortCode, requestActor(r)); err != nil {
 		h.handleServiceError(w, err, "failed to delete url")
 		return
 	}
@@ -200,11 +614,30 @@ func (h *URLHandler) handleServiceError(w http.ResponseWriter, err error, logMsg
 		return
 	}
 
+	if errors.Is(err, domain.ErrURLDisabled) {
+		// The link may be enabled again, so the response must not be cached.
+		w.Header().Set("Cache-Control", "no-store")
+		h.respondError(w, http.StatusForbidden, "url is disabled", "")
+		return
+	}
+
 	if errors.Is(err, domain.ErrURLExpired) {
 		h.respondError(w, http.StatusGone, "url has expired", "")
 		return
 	}
 
+	if errors.Is(err, domain.ErrClickLimitReached) {
+		h.respondError(w, http.StatusGone, "url click limit reached", "")
+		return
+	}
+
+	if errors.Is(err, domain.ErrURLNotActive) {
+		// The link goes live later, so the response must not outlive its activation.
+		w.Header().Set("Cache-Control", "no-store")
+		h.respondError(w, h.service.NotLiveStatus(), "url is not active yet", "")
+		return
+	}
+
 	if errors.Is(err, domain.ErrInvalidURL) {
 		h.respondError(w, http.StatusBadRequest, "invalid url", "")
 		return
@@ -220,6 +653,86 @@ func (h *URLHandler) handleServiceError(w http.ResponseWriter, err error, logMsg
 		return
 	}
 
+	if errors.Is(err, domain.ErrInvalidStatsQuery) {
+		h.respondError(w, http.StatusBadRequest, "invalid stats query", err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrInvalidRedirectType) {
+		h.respondError(w, http.StatusBadRequest, "invalid redirect type", "must be 301, 302, 307 or 308")
+		return
+	}
+
+	if errors.Is(err, domain.ErrInvalidQueryPrecedence) {
+		h.respondError(w, http.StatusBadRequest, "invalid query precedence", "must be destination or request")
+		return
+	}
+
+	if errors.Is(err, domain.ErrUTMTemplateNotFound) {
+		h.respondError(w, http.StatusBadRequest, "utm template not found", "")
+		return
+	}
+
+	if errors.Is(err, domain.ErrInvalidUTM) {
+		h.respondError(w, http.StatusBadRequest, "invalid utm parameters", err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrInvalidTargetingRule) {
+		h.respondError(w, http.StatusBadRequest, "invalid targeting rule", err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrInvalidVariant) {
+		h.respondError(w, http.StatusBadRequest, "invalid variant", err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrInvalidPassword) {
+		h.respondError(w, http.StatusBadRequest, "invalid password", err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrInvalidClickLimit) {
+		h.respondError(w, http.StatusBadRequest, "invalid click limit", "max_clicks must be positive")
+		return
+	}
+
+	if errors.Is(err, domain.ErrRevisionNotFound) {
+		h.respondError(w, http.StatusNotFound, "revision not found", "")
+		return
+	}
+
+	if errors.Is(err, domain.ErrVersionMismatch) {
+		h.respondError(w, http.StatusPreconditionFailed, "url was modified by another request", "")
+		return
+	}
+
+	if errors.Is(err, domain.ErrInvalidExpiry) {
+		h.respondError(w, http.StatusBadRequest, "invalid expiry", err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrInvalidActivation) {
+		h.respondError(w, http.StatusBadRequest, "invalid activation", err.Error())
+		return
+	}
+
+	if errors.Is(err, domain.ErrInvalidIdempotencyKey) {
+		h.respondError(w, http.StatusBadRequest, "invalid idempotency key", "must be between 1 and 255 characters")
+		return
+	}
+
+	if errors.Is(err, domain.ErrIdempotencyKeyInProgress) {
+		h.respondError(w, http.StatusConflict, "request with this idempotency key is in progress", "")
+		return
+	}
+
+	if errors.Is(err, domain.ErrIdempotencyKeyReused) {
+		h.respondError(w, http.StatusUnprocessableEntity, "idempotency key reused with a different request", "")
+		return
+	}
+
 	h.logger.Error(logMsg, slog.String("error", err.Error()))
 	h.respondError(w, http.StatusInternalServerError, "internal server error", "")
 }
@@ -240,3 +753,99 @@ func (h *URLHandler) respondError(w http.ResponseWriter, status int, error, mess
 	}
 	h.respondJSON(w, status, response)
 }
+
+// parseTimeParam parses an optional RFC 3339 query parameter; empty yields the zero time.
+func parseTimeParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// ifMatchVersion reads the URL version required by the If-Match header. It returns 0 for "*" and
+// writes an error response and returns false when the header is missing or does not name a version.
+func (h *URLHandler) ifMatchVersion(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		h.respondError(w, http.StatusPreconditionRequired, "If-Match header is required", "send the ETag returned by GET /api/urls/{shortCode}, or * to update unconditionally")
+		return 0, false
+	}
+
+	if ifMatch == "*" {
+		return 0, true
+	}
+
+	version, ok := parseETag(ifMatch)
+	if !ok {
+		h.respondError(w, http.StatusPreconditionFailed, "url was modified by another request", "")
+		return 0, false
+	}
+
+	return version, true
+}
+
+// formatETag returns the strong entity tag for a URL version.
+func formatETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseETag extracts the URL version from an If-Match entity tag. Weak tags are accepted.
+func parseETag(tag string) (int64, bool) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+	if err != nil || version < 1 {
+		return 0, false
+	}
+
+	return version, true
+}
+
+// maxActorLength is the size of the changed_by, created_by and revoked_by columns, in characters.
+const maxActorLength = 255
+
+// maxRequestIDLength is the size of the clicks.request_id column, in characters. The request ID comes
+// from the client's X-Request-Id header when it sends one.
+const maxRequestIDLength = 128
+
+// requestActor identifies who made a change: the name of the API key that authenticated the request,
+// or the client IP without one. The X-Actor header is client-supplied, so it is only appended as a
+// note and never replaces the key.
+func requestActor(r *http.Request) string {
+	actor := clientIP(r)
+	if key := requestAPIKey(r); key != nil {
+		actor = key.Name
+	}
+	if claimed := strings.TrimSpace(r.Header.Get("X-Actor")); claimed != "" && claimed != actor {
+		actor += " (X-Actor: " + claimed + ")"
+	}
+	return truncateRunes(actor, maxActorLength)
+}
+
+// truncateRunes shortens s to at most limit runes without splitting a multi-byte character.
+func truncateRunes(s string, limit int) string {
+	count := 0
+	for i := range s {
+		if count == limit {
+			return s[:i]
+		}
+		count++
+	}
+	return s
+}
+
+// variantCookieName returns the cookie that pins a visitor to one variant of shortCode.
+func variantCookieName(shortCode string) string {
+	return "variant_" + shortCode
+}
+
+// clientIP returns the client address, which middleware.RealIP has already resolved into RemoteAddr.
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
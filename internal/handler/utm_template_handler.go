@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/edson-mazvila/url-shortener/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// UTMTemplateHandler handles HTTP requests for UTM templates.
+type UTMTemplateHandler struct {
+	service *service.UTMTemplateService
+	logger  *slog.Logger
+}
+
+// NewUTMTemplateHandler creates a new UTM template handler.
+func NewUTMTemplateHandler(service *service.UTMTemplateService, logger *slog.Logger) *UTMTemplateHandler {
+	return &UTMTemplateHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// UTMTemplateRequest represents the request body for creating or replacing a UTM template.
+type UTMTemplateRequest struct {
+	Name string `json:"name,omitempty"`
+	domain.UTMParams
+}
+
+// ListUTMTemplatesResponse represents the response for listing UTM templates.
+type ListUTMTemplatesResponse struct {
+	Templates []*domain.UTMTemplate `json:"templates"`
+}
+
+// CreateTemplate handles POST /api/utm-templates
+func (h *UTMTemplateHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	var req UTMTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("invalid request body", slog.String("error", err.Error()))
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	template, err := h.service.CreateTemplate(r.Context(), req.Name, req.UTMParams)
+	if err != nil {
+		h.handleServiceError(w, err, "failed to create utm template")
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, template)
+}
+
+// ListTemplates handles GET /api/utm-templates
+func (h *UTMTemplateHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.service.ListTemplates(r.Context())
+	if err != nil {
+		h.handleServiceError(w, err, "failed to list utm templates")
+		return
+	}
+
+	if templates == nil {
+		templates = []*domain.UTMTemplate{}
+	}
+
+	h.respondJSON(w, http.StatusOK, ListUTMTemplatesResponse{Templates: templates})
+}
+
+// GetTemplate handles GET /api/utm-templates/{name}
+func (h *UTMTemplateHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	template, err := h.service.GetTemplate(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		h.handleServiceError(w, err, "failed to get utm template")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, template)
+}
+
+// UpdateTemplate handles PUT /api/utm-templates/{name}
+func (h *UTMTemplateHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	var req UTMTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("invalid request body", slog.String("error", err.Error()))
+		h.respondError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if req.Name != "" && req.Name != name {
+		h.respondError(w, http.StatusBadRequest, "invalid utm parameters", "templates cannot be renamed")
+		return
+	}
+
+	template, err := h.service.UpdateTemplate(r.Context(), name, req.UTMParams)
+	if err != nil {
+		h.handleServiceError(w, err, "failed to update utm template")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, template)
+}
+
+// DeleteTemplate handles DELETE /api/utm-templates/{name}
+func (h *UTMTemplateHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteTemplate(r.Context(), chi.URLParam(r, "name")); err != nil {
+		h.handleServiceError(w, err, "failed to delete utm template")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UTMTemplateHandler) handleServiceError(w http.ResponseWriter, err error, logMsg string) {
+	if errors.Is(err, domain.ErrUTMTemplateNotFound) {
+		h.respondError(w, http.StatusNotFound, "utm template not found", "")
+		return
+	}
+
+	if errors.Is(err, domain.ErrUTMTemplateExists) {
+		h.respondError(w, http.StatusConflict, "utm template already exists", "")
+		return
+	}
+
+	if errors.Is(err, domain.ErrInvalidUTM) {
+		h.respondError(w, http.StatusBadRequest, "invalid utm parameters", err.Error())
+		return
+	}
+
+	h.logger.Error(logMsg, slog.String("error", err.Error()))
+	h.respondError(w, http.StatusInternalServerError, "internal server error", "")
+}
+
+func (h *UTMTemplateHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.Error("failed to encode response", slog.String("error", err.Error()))
+	}
+}
+
+func (h *UTMTemplateHandler) respondError(w http.ResponseWriter, status int, error, message string) {
+	h.respondJSON(w, status, ErrorResponse{
+		Error:   error,
+		Message: message,
+	})
+}
@@ -0,0 +1,54 @@
+package migration
+
+import (
+	"io/fs"
+
+	"github.com/edson-mazvila/url-shortener/migrations"
+)
+
+// advisoryLockKey serialises migrations across replicas sharing one PostgreSQL database.
+const advisoryLockKey int64 = 7_146_282_553_190_402
+
+// Dialect holds the database-specific SQL used by the migrator.
+type Dialect struct {
+	Name   string
+	Source fs.FS
+
+	createTable   string
+	insertVersion string
+	deleteVersion string
+	lock          string
+	unlock        string
+}
+
+// Postgres is the dialect for PostgreSQL, guarded by a session-level advisory lock.
+var Postgres = Dialect{
+	Name:   "postgres",
+	Source: migrations.Postgres(),
+	createTable: `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+		)
+	`,
+	insertVersion: `INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`,
+	deleteVersion: `DELETE FROM schema_migrations WHERE version = $1`,
+	lock:          `SELECT pg_advisory_lock($1)`,
+	unlock:        `SELECT pg_advisory_unlock($1)`,
+}
+
+// SQLite is the dialect for the embedded SQLite backend.
+var SQLite = Dialect{
+	Name:   "sqlite",
+	Source: migrations.SQLite(),
+	createTable: `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`,
+	insertVersion: `INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+	deleteVersion: `DELETE FROM schema_migrations WHERE version = ?`,
+}
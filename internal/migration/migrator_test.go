@@ -0,0 +1,185 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"testing"
+
+	"github.com/edson-mazvila/url-shortener/internal/config"
+	"github.com/edson-mazvila/url-shortener/internal/storage"
+)
+
+func newTestMigrator(t *testing.T) (*Migrator, *sql.DB) {
+	t.Helper()
+
+	logger := slog.New(slog.DiscardHandler)
+	db, err := storage.NewSQLiteDB(context.Background(), &config.DatabaseConfig{
+		Driver:       config.DriverSQLite,
+		Path:         config.SQLiteMemoryPath,
+		MaxOpenConns: 1,
+		MaxIdleConns: 1,
+	}, logger)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	migrator, err := NewMigrator(db.DB(), SQLite, logger)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+
+	return migrator, db.DB()
+}
+
+// tableExists reports whether the SQLite schema has a table called name.
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name).Scan(&count); err != nil {
+		t.Fatalf("query sqlite_master: %v", err)
+	}
+	return count > 0
+}
+
+// appliedVersion returns the highest applied version according to Status.
+func appliedVersion(t *testing.T, migrator *Migrator) int64 {
+	t.Helper()
+
+	statuses, err := migrator.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+
+	var version int64
+	for _, status := range statuses {
+		if status.Applied {
+			if status.AppliedAt == nil {
+				t.Errorf("migration %d is applied without an applied_at", status.Version)
+			}
+			version = status.Version
+		} else if status.AppliedAt != nil {
+			t.Errorf("pending migration %d has an applied_at", status.Version)
+		}
+	}
+	return version
+}
+
+func TestMigratorSQLite(t *testing.T) {
+	ctx := context.Background()
+	migrator, db := newTestMigrator(t)
+	latest := migrator.Latest()
+
+	if latest < 2 {
+		t.Fatalf("Latest() = %d, want at least two migrations", latest)
+	}
+
+	steps := []struct {
+		name        string
+		run         func() (int, error)
+		wantCount   int
+		wantVersion int64
+		// tables maps table names to whether they must exist after the step.
+		tables map[string]bool
+	}{
+		{
+			name:        "up from empty",
+			run:         func() (int, error) { return migrator.Up(ctx) },
+			wantCount:   int(latest),
+			wantVersion: latest,
+			tables:      map[string]bool{"urls": true, "clicks": true, "api_keys": true},
+		},
+		{
+			name:        "up again",
+			run:         func() (int, error) { return migrator.Up(ctx) },
+			wantCount:   0,
+			wantVersion: latest,
+			tables:      map[string]bool{"api_keys": true},
+		},
+		{
+			name:        "down",
+			run:         func() (int, error) { return migrator.Down(ctx) },
+			wantCount:   1,
+			wantVersion: latest - 1,
+			tables:      map[string]bool{"urls": true, "idempotency_keys": true, "api_keys": true},
+		},
+		{
+			name:        "to 2",
+			run:         func() (int, error) { return migrator.To(ctx, 2) },
+			wantCount:   int(latest - 3),
+			wantVersion: 2,
+			tables:      map[string]bool{"urls": true, "clicks": true, "idempotency_keys": false},
+		},
+		{
+			name:        "to latest",
+			run:         func() (int, error) { return migrator.To(ctx, latest) },
+			wantCount:   int(latest - 2),
+			wantVersion: latest,
+			tables:      map[string]bool{"idempotency_keys": true, "api_keys": true},
+		},
+		{
+			name:        "to 0",
+			run:         func() (int, error) { return migrator.To(ctx, 0) },
+			wantCount:   int(latest),
+			wantVersion: 0,
+			tables:      map[string]bool{"urls": false, "clicks": false, "schema_migrations": true},
+		},
+		{
+			name:        "down from empty",
+			run:         func() (int, error) { return migrator.Down(ctx) },
+			wantCount:   0,
+			wantVersion: 0,
+		},
+	}
+
+	for _, step := range steps {
+		count, err := step.run()
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if count != step.wantCount {
+			t.Errorf("%s: migrated %d, want %d", step.name, count, step.wantCount)
+		}
+		if version := appliedVersion(t, migrator); version != step.wantVersion {
+			t.Errorf("%s: version %d, want %d", step.name, version, step.wantVersion)
+		}
+		for table, want := range step.tables {
+			if got := tableExists(t, db, table); got != want {
+				t.Errorf("%s: table %s exists = %v, want %v", step.name, table, got, want)
+			}
+		}
+	}
+}
+
+func TestMigratorToUnknownVersion(t *testing.T) {
+	migrator, _ := newTestMigrator(t)
+
+	if _, err := migrator.To(context.Background(), migrator.Latest()+1); err == nil {
+		t.Fatal("To(unknown version) succeeded, want an error")
+	}
+	if version := appliedVersion(t, migrator); version != 0 {
+		t.Errorf("version after failed migration = %d, want 0", version)
+	}
+}
+
+func TestMigrationsHaveUpAndDownScripts(t *testing.T) {
+	for _, dialect := range []Dialect{Postgres, SQLite} {
+		t.Run(dialect.Name, func(t *testing.T) {
+			migrations, err := load(dialect.Source)
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+
+			for i, mig := range migrations {
+				if mig.Version != int64(i+1) {
+					t.Errorf("migration %d_%s is numbered out of sequence, want %d", mig.Version, mig.Name, i+1)
+				}
+				if mig.Up == "" || mig.Down == "" {
+					t.Errorf("migration %d_%s is missing its up or down script", mig.Version, mig.Name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Package pages renders the HTML pages shown to visitors of short links. Every page has a built-in
+// template, which a file of the same name in the configured template directory replaces. Error pages
+// can also be given per status code, such as 404.html.
+package pages
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Page names, which are also the file names of their templates.
+const (
+	Preview = "preview.html"
+	// Error is the error page used for status codes without a page of their own.
+	Error = "error.html"
+)
+
+var names = []string{Preview, Error}
+
+// statusPattern matches the names of per status code error pages in the template directory.
+const statusPattern = "[1-5][0-9][0-9].html"
+
+//go:embed templates/*.html
+var defaults embed.FS
+
+// PreviewData is passed to the preview page template.
+type PreviewData struct {
+	ShortCode string
+	ShortURL  string
+	// Destination is where the visitor will be sent.
+	Destination string
+	CreatedAt   time.Time
+	// ContinueURL is the URL the continue form posts to.
+	ContinueURL string
+	// Variant names the variant that chose Destination; the continue form sends it back so the
+	// visitor ends up where the preview said.
+	Variant string
+}
+
+// ErrorData is passed to error page templates.
+type ErrorData struct {
+	Status    int
+	Title     string
+	Message   string
+	ShortCode string
+}
+
+// Renderer renders pages from their parsed templates.
+type Renderer struct {
+	templates map[string]*template.Template
+}
+
+// New parses the page templates, preferring files in dir over the built-in ones. An empty dir uses
+// only the built-in templates.
+func New(dir string) (*Renderer, error) {
+	r := &Renderer{templates: make(map[string]*template.Template, len(names))}
+
+	for _, name := range names {
+		source, err := readTemplate(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.parse(name, source); err != nil {
+			return nil, err
+		}
+	}
+
+	if dir == "" {
+		return r, nil
+	}
+
+	statusPages, err := filepath.Glob(filepath.Join(dir, statusPattern))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list error page templates: %w", err)
+	}
+	for _, path := range statusPages {
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read page template %s: %w", filepath.Base(path), err)
+		}
+		if err := r.parse(filepath.Base(path), source); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// parse parses source as the template of the page name.
+func (r *Renderer) parse(name string, source []byte) error {
+	tmpl, err := template.New(name).Parse(string(source))
+	if err != nil {
+		return fmt.Errorf("failed to parse page template %s: %w", name, err)
+	}
+	r.templates[name] = tmpl
+	return nil
+}
+
+// readTemplate returns the template named name from dir, or the built-in one if dir has none.
+func readTemplate(dir, name string) ([]byte, error) {
+	if dir != "" {
+		source, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			return source, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read page template %s: %w", name, err)
+		}
+	}
+
+	return defaults.ReadFile("templates/" + name)
+}
+
+// Render writes the page name rendered with data to w. Nothing is written if rendering fails.
+func (r *Renderer) Render(w io.Writer, name string, data any) error {
+	tmpl, ok := r.templates[name]
+	if !ok {
+		return fmt.Errorf("unknown page %s", name)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("failed to render page %s: %w", name, err)
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ErrorPage returns the page to render for status: the page named after the status code if the
+// template directory has one, and Error otherwise.
+func (r *Renderer) ErrorPage(status int) string {
+	if name := strconv.Itoa(status) + ".html"; r.templates[name] != nil {
+		return name
+	}
+	return Error
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// APIKeyRepository handles database operations for API keys.
+type APIKeyRepository struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewAPIKeyRepository creates a new API key repository.
+func NewAPIKeyRepository(pool *pgxpool.Pool, logger *slog.Logger) *APIKeyRepository {
+	return &APIKeyRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// CreateAPIKey stores a new API key.
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, key *domain.APIKey) error {
+	query := `
+		INSERT INTO api_keys (name, prefix, key_hash, admin, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	err := r.pool.QueryRow(ctx, query,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		key.Admin,
+		key.CreatedBy,
+		key.CreatedAt,
+	).Scan(&key.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	r.logger.Debug("api key created", slog.Int64("id", key.ID), slog.String("prefix", key.Prefix))
+
+	return nil
+}
+
+// GetAPIKey retrieves an API key by ID.
+func (r *APIKeyRepository) GetAPIKey(ctx context.Context, id int64) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = $1`
+
+	key, err := scanAPIKey(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// GetAPIKeyByHash retrieves an API key by the hash of its secret.
+func (r *APIKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+
+	key, err := scanAPIKey(r.pool.QueryRow(ctx, query, keyHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// ListAPIKeys retrieves all API keys ordered by ID.
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY id`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*domain.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RenameAPIKey changes the name of an API key.
+func (r *APIKeyRepository) RenameAPIKey(ctx context.Context, id int64, name string) error {
+	query := `UPDATE api_keys SET name = $1 WHERE id = $2`
+
+	result, err := r.pool.Exec(ctx, query, name, id)
+	if err != nil {
+		return fmt.Errorf("failed to rename api key: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// RevokeAPIKey marks an API key as revoked.
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, id int64, revokedAt time.Time) error {
+	query := `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $1) WHERE id = $2`
+
+	result, err := r.pool.Exec(ctx, query, revokedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	r.logger.Debug("api key revoked", slog.Int64("id", id))
+
+	return nil
+}
+
+// TouchAPIKey records when an API key was last used.
+func (r *APIKeyRepository) TouchAPIKey(ctx context.Context, id int64, usedAt time.Time) error {
+	query := `UPDATE api_keys SET last_used_at = $1 WHERE id = $2`
+
+	if _, err := r.pool.Exec(ctx, query, usedAt, id); err != nil {
+		return fmt.Errorf("failed to update api key last use: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,305 @@
+package repository
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/config"
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"golang.org/x/sync/singleflight"
+)
+
+// cacheLoadTimeout bounds a shared cache miss load, which no longer ends with the request that started it.
+const cacheLoadTimeout = 5 * time.Second
+
+// CacheStats reports the effectiveness of the short code cache.
+type CacheStats struct {
+	Enabled  bool   `json:"enabled"`
+	Hits     uint64 `json:"hits"`
+	Misses   uint64 `json:"misses"`
+	Size     int    `json:"size"`
+	Capacity int    `json:"capacity"`
+}
+
+// CachedURLRepository is a read-through LRU cache for GetByShortCode in front of another URLStore.
+// Both hits and not-found results are cached. Only lookups whose context was marked with AllowCached
+// use the cache; all others read the underlying store.
+//
+// Writes evict entries of this process only. Other processes sharing the database keep serving their
+// cached entries until the TTL has passed, so with several replicas an edit, disable or delete takes
+// up to CacheConfig.TTL to reach every redirect.
+type CachedURLRepository struct {
+	URLStore
+
+	config *config.CacheConfig
+	logger *slog.Logger
+	group  singleflight.Group
+
+	mu         sync.Mutex
+	items      map[string]*list.Element
+	order      *list.List
+	generation uint64
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+type cacheEntry struct {
+	shortCode string
+	url       *domain.URL
+	expiresAt time.Time
+}
+
+var _ URLStore = (*CachedURLRepository)(nil)
+
+// allowCachedKey is the context key set by AllowCached.
+type allowCachedKey struct{}
+
+// AllowCached returns a context whose short code lookups may be answered from a cache, and so may be
+// stale by up to the cache TTL. It is meant for redirects; management reads must see the database.
+func AllowCached(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allowCachedKey{}, true)
+}
+
+func allowsCached(ctx context.Context) bool {
+	allowed, _ := ctx.Value(allowCachedKey{}).(bool)
+	return allowed
+}
+
+// NewCachedURLRepository wraps store with a size-bounded, TTL-aware cache.
+func NewCachedURLRepository(store URLStore, cfg *config.CacheConfig, logger *slog.Logger) *CachedURLRepository {
+	return &CachedURLRepository{
+		URLStore: store,
+		config:   cfg,
+		logger:   logger,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Create creates a new URL and drops any cached not-found entry for its short code.
+func (c *CachedURLRepository) Create(ctx context.Context, url *domain.URL, change domain.Change) error {
+	err := c.URLStore.Create(ctx, url, change)
+	c.invalidate(url.ShortCode)
+	return err
+}
+
+// GetByShortCode returns the cached URL, loading it from the underlying store on a miss, if ctx allows
+// cached results. Concurrent misses for the same short code share a single query, which keeps running
+// when the caller that started it gives up; each caller stops waiting when its own ctx is done.
+func (c *CachedURLRepository) GetByShortCode(ctx context.Context, shortCode string) (*domain.URL, error) {
+	if !allowsCached(ctx) {
+		return c.URLStore.GetByShortCode(ctx, shortCode)
+	}
+
+	if url, found, ok := c.lookup(shortCode); ok {
+		c.hits.Add(1)
+		if !found {
+			return nil, domain.ErrURLNotFound
+		}
+		return url, nil
+	}
+
+	c.misses.Add(1)
+
+	results := c.group.DoChan(shortCode, func() (any, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cacheLoadTimeout)
+		defer cancel()
+
+		c.mu.Lock()
+		generation := c.generation
+		c.mu.Unlock()
+
+		url, err := c.URLStore.GetByShortCode(loadCtx, shortCode)
+		switch {
+		case err == nil:
+			c.store(shortCode, url, c.config.TTL, generation)
+		case errors.Is(err, domain.ErrURLNotFound):
+			c.store(shortCode, nil, c.config.NegativeTTL, generation)
+		}
+
+		return url, err
+	})
+
+	select {
+	case result := <-results:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return cloneURL(result.Val.(*domain.URL)), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Update updates a URL and evicts it from the cache.
+func (c *CachedURLRepository) Update(ctx context.Context, url *domain.URL) error {
+	err := c.URLStore.Update(ctx, url)
+	c.invalidate(url.ShortCode)
+	return err
+}
+
+// UpdateAttributes updates the editable attributes of a URL and evicts it from the cache.
+func (c *CachedURLRepository) UpdateAttributes(ctx context.Context, url *domain.URL, change domain.Change) error {
+	err := c.URLStore.UpdateAttributes(ctx, url, change)
+	c.invalidate(url.ShortCode)
+	return err
+}
+
+// ConsumeClick counts a redirect against the click limit of a URL and evicts it from the cache, so its
+// remaining clicks are read fresh.
+func (c *CachedURLRepository) ConsumeClick(ctx context.Context, url *domain.URL) error {
+	err := c.URLStore.ConsumeClick(ctx, url)
+	c.invalidate(url.ShortCode)
+	return err
+}
+
+// DeleteExpired deletes expired and exhausted URLs and evicts them from the cache. Entries kept for
+// their fallback URL are evicted too, which only costs a reload.
+func (c *CachedURLRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	count, err := c.URLStore.DeleteExpired(ctx)
+
+	now := time.Now()
+	c.mu.Lock()
+	c.generation++
+	for shortCode, elem := range c.items {
+		entry := elem.Value.(*cacheEntry)
+		if entry.url != nil && ((entry.url.ExpiresAt != nil && entry.url.ExpiresAt.Before(now)) || entry.url.IsExhausted()) {
+			c.order.Remove(elem)
+			delete(c.items, shortCode)
+		}
+	}
+	c.mu.Unlock()
+
+	return count, err
+}
+
+// IncrementAccessCounts adds buffered access counts to the stored URLs and to their cached entries.
+func (c *CachedURLRepository) IncrementAccessCounts(ctx context.Context, deltas map[string]AccessDelta) error {
+	err := c.URLStore.IncrementAccessCounts(ctx, deltas)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Loads in flight may have read the counts from before the increment.
+	c.generation++
+	for shortCode, delta := range deltas {
+		elem, exists := c.items[shortCode]
+		if !exists {
+			continue
+		}
+
+		entry := elem.Value.(*cacheEntry)
+		if entry.url == nil {
+			continue
+		}
+
+		if err != nil {
+			c.order.Remove(elem)
+			delete(c.items, shortCode)
+			continue
+		}
+
+		entry.url.AccessCount += delta.Count
+		if entry.url.LastAccessed == nil || delta.LastAccessed.After(*entry.url.LastAccessed) {
+			lastAccessed := delta.LastAccessed
+			entry.url.LastAccessed = &lastAccessed
+		}
+	}
+
+	return err
+}
+
+// Stats returns the cache hit and miss counters.
+func (c *CachedURLRepository) Stats() CacheStats {
+	c.mu.Lock()
+	size := len(c.items)
+	c.mu.Unlock()
+
+	return CacheStats{
+		Enabled:  true,
+		Hits:     c.hits.Load(),
+		Misses:   c.misses.Load(),
+		Size:     size,
+		Capacity: c.config.Size,
+	}
+}
+
+// lookup returns a copy of the cached URL. found is false for cached not-found entries;
+// ok is false when the short code is not cached or its entry has expired.
+func (c *CachedURLRepository) lookup(shortCode string) (url *domain.URL, found bool, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, exists := c.items[shortCode]
+	if !exists {
+		return nil, false, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.items, shortCode)
+		return nil, false, false
+	}
+
+	c.order.MoveToFront(elem)
+
+	if entry.url == nil {
+		return nil, false, true
+	}
+
+	return cloneURL(entry.url), true, true
+}
+
+// store caches a loaded value unless the cache was invalidated while it was being loaded.
+func (c *CachedURLRepository) store(shortCode string, url *domain.URL, ttl time.Duration, generation uint64) {
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	entry := &cacheEntry{
+		shortCode: shortCode,
+		expiresAt: time.Now().Add(ttl),
+	}
+	if url != nil {
+		entry.url = cloneURL(url)
+	}
+
+	if elem, exists := c.items[shortCode]; exists {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[shortCode] = c.order.PushFront(entry)
+
+	for c.order.Len() > c.config.Size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).shortCode)
+	}
+}
+
+func (c *CachedURLRepository) invalidate(shortCode string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	if elem, exists := c.items[shortCode]; exists {
+		c.order.Remove(elem)
+		delete(c.items, shortCode)
+	}
+}
@@ -0,0 +1,312 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/config"
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+)
+
+// countingStore counts the lookups that reach the underlying store and can hold them until released or
+// their context is done.
+type countingStore struct {
+	URLStore
+
+	lookups atomic.Int64
+	gate    chan struct{}
+}
+
+func (s *countingStore) GetByShortCode(ctx context.Context, shortCode string) (*domain.URL, error) {
+	s.lookups.Add(1)
+	if s.gate != nil {
+		select {
+		case <-s.gate:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return s.URLStore.GetByShortCode(ctx, shortCode)
+}
+
+func newTestCache(t *testing.T) (*CachedURLRepository, *countingStore) {
+	t.Helper()
+
+	logger := slog.New(slog.DiscardHandler)
+	store := &countingStore{URLStore: NewMemoryURLRepository(logger)}
+	cache := NewCachedURLRepository(store, &config.CacheConfig{
+		Enabled:     true,
+		Size:        100,
+		TTL:         time.Minute,
+		NegativeTTL: time.Minute,
+	}, logger)
+
+	return cache, store
+}
+
+func TestCachedURLRepositoryInvalidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(ctx context.Context, cache *CachedURLRepository, url *domain.URL) error
+		check func(t *testing.T, url *domain.URL, err error)
+	}{
+		{
+			name: "update",
+			write: func(ctx context.Context, cache *CachedURLRepository, url *domain.URL) error {
+				url.AccessCount = 42
+				return cache.Update(ctx, url)
+			},
+			check: func(t *testing.T, url *domain.URL, err error) {
+				if err != nil {
+					t.Fatalf("GetByShortCode after write: %v", err)
+				}
+				if url.AccessCount != 42 {
+					t.Errorf("access count = %d, want 42", url.AccessCount)
+				}
+			},
+		},
+		{
+			name: "update attributes",
+			write: func(ctx context.Context, cache *CachedURLRepository, url *domain.URL) error {
+				url.OriginalURL = "https://example.com/changed"
+				return cache.UpdateAttributes(ctx, url, domain.Change{Action: domain.RevisionUpdate})
+			},
+			check: func(t *testing.T, url *domain.URL, err error) {
+				if err != nil {
+					t.Fatalf("GetByShortCode after write: %v", err)
+				}
+				if url.OriginalURL != "https://example.com/changed" {
+					t.Errorf("original url = %q, want the updated one", url.OriginalURL)
+				}
+			},
+		},
+		{
+			name: "consume click",
+			write: func(ctx context.Context, cache *CachedURLRepository, url *domain.URL) error {
+				return cache.ConsumeClick(ctx, url)
+			},
+			check: func(t *testing.T, url *domain.URL, err error) {
+				if err != nil {
+					t.Fatalf("GetByShortCode after write: %v", err)
+				}
+				if url.UsedClicks != 1 {
+					t.Errorf("used clicks = %d, want 1", url.UsedClicks)
+				}
+			},
+		},
+		{
+			name: "delete expired",
+			write: func(ctx context.Context, cache *CachedURLRepository, url *domain.URL) error {
+				past := time.Now().Add(-time.Minute)
+				url.ExpiresAt = &past
+				if err := cache.URLStore.UpdateAttributes(ctx, url, domain.Change{Action: domain.RevisionUpdate}); err != nil {
+					return err
+				}
+				// The stale entry still looks current to the cache, so refresh it before cleaning up.
+				cache.invalidate(url.ShortCode)
+				if _, err := cache.GetByShortCode(AllowCached(ctx), url.ShortCode); err != nil {
+					return err
+				}
+				_, err := cache.DeleteExpired(ctx)
+				return err
+			},
+			check: func(t *testing.T, _ *domain.URL, err error) {
+				if !errors.Is(err, domain.ErrURLNotFound) {
+					t.Fatalf("GetByShortCode after cleanup: got %v, want ErrURLNotFound", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := AllowCached(context.Background())
+			cache, store := newTestCache(t)
+			maxClicks := int64(5)
+			created := createTestURL(t, cache, "cached", func(url *domain.URL) { url.MaxClicks = &maxClicks })
+
+			if _, err := cache.GetByShortCode(ctx, created.ShortCode); err != nil {
+				t.Fatalf("GetByShortCode: %v", err)
+			}
+			url, err := cache.GetByShortCode(ctx, created.ShortCode)
+			if err != nil {
+				t.Fatalf("GetByShortCode: %v", err)
+			}
+			if got := store.lookups.Load(); got != 1 {
+				t.Fatalf("store lookups before write = %d, want 1", got)
+			}
+
+			if err := tt.write(ctx, cache, url); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			before := store.lookups.Load()
+
+			fresh, err := cache.GetByShortCode(ctx, created.ShortCode)
+			tt.check(t, fresh, err)
+			if store.lookups.Load() != before+1 {
+				t.Errorf("lookup after write was served from the cache")
+			}
+		})
+	}
+}
+
+func TestCachedURLRepositoryNegativeCaching(t *testing.T) {
+	ctx := AllowCached(context.Background())
+	cache, store := newTestCache(t)
+
+	for range 3 {
+		if _, err := cache.GetByShortCode(ctx, "missing"); !errors.Is(err, domain.ErrURLNotFound) {
+			t.Fatalf("GetByShortCode: got %v, want ErrURLNotFound", err)
+		}
+	}
+	if got := store.lookups.Load(); got != 1 {
+		t.Errorf("store lookups for a missing code = %d, want 1", got)
+	}
+
+	createTestURL(t, cache, "missing", nil)
+
+	if _, err := cache.GetByShortCode(ctx, "missing"); err != nil {
+		t.Errorf("GetByShortCode after create: %v", err)
+	}
+	if got := store.lookups.Load(); got != 2 {
+		t.Errorf("store lookups after create = %d, want 2", got)
+	}
+}
+
+func TestCachedURLRepositorySingleflight(t *testing.T) {
+	ctx := AllowCached(context.Background())
+	cache, store := newTestCache(t)
+	createTestURL(t, cache, "popular", nil)
+
+	store.gate = make(chan struct{})
+
+	const callers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cache.GetByShortCode(ctx, "popular")
+			errs <- err
+		}()
+	}
+
+	// Every caller has missed the cache; give the last ones a moment to join the blocked load.
+	for cache.misses.Load() < callers {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(store.gate)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("GetByShortCode: %v", err)
+		}
+	}
+	if got := store.lookups.Load(); got != 1 {
+		t.Errorf("store lookups for concurrent misses = %d, want 1", got)
+	}
+}
+
+func TestCachedURLRepositorySingleflightOutlivesCanceledCaller(t *testing.T) {
+	cache, store := newTestCache(t)
+	createTestURL(t, cache, "popular", nil)
+
+	store.gate = make(chan struct{})
+
+	first, cancel := context.WithCancel(AllowCached(context.Background()))
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := cache.GetByShortCode(first, "popular")
+		firstErr <- err
+	}()
+	for store.lookups.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	const callers = 5
+	errs := make(chan error, callers)
+	for range callers {
+		go func() {
+			_, err := cache.GetByShortCode(AllowCached(context.Background()), "popular")
+			errs <- err
+		}()
+	}
+	for cache.misses.Load() < callers+1 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled caller: got err %v, want context.Canceled", err)
+	}
+
+	close(store.gate)
+	for range callers {
+		if err := <-errs; err != nil {
+			t.Errorf("GetByShortCode after another caller gave up: %v", err)
+		}
+	}
+	if got := store.lookups.Load(); got != 1 {
+		t.Errorf("store lookups = %d, want 1", got)
+	}
+}
+
+func TestCachedURLRepositoryBypassesCacheWithoutAllowCached(t *testing.T) {
+	ctx := context.Background()
+	cache, store := newTestCache(t)
+	createTestURL(t, cache, "managed", nil)
+
+	for range 3 {
+		if _, err := cache.GetByShortCode(ctx, "managed"); err != nil {
+			t.Fatalf("GetByShortCode: %v", err)
+		}
+	}
+
+	if got := store.lookups.Load(); got != 3 {
+		t.Errorf("store lookups = %d, want 3", got)
+	}
+	if stats := cache.Stats(); stats.Size != 0 || stats.Hits != 0 || stats.Misses != 0 {
+		t.Errorf("stats = %+v, want an unused cache", stats)
+	}
+}
+
+func TestCachedURLRepositoryIncrementAccessCounts(t *testing.T) {
+	ctx := AllowCached(context.Background())
+	cache, store := newTestCache(t)
+	createTestURL(t, cache, "counted", nil)
+
+	if _, err := cache.GetByShortCode(ctx, "counted"); err != nil {
+		t.Fatalf("GetByShortCode: %v", err)
+	}
+
+	accessed := time.Now().Truncate(time.Second)
+	if err := cache.IncrementAccessCounts(ctx, map[string]AccessDelta{
+		"counted": {Count: 3, LastAccessed: accessed},
+	}); err != nil {
+		t.Fatalf("IncrementAccessCounts: %v", err)
+	}
+
+	url, err := cache.GetByShortCode(ctx, "counted")
+	if err != nil {
+		t.Fatalf("GetByShortCode: %v", err)
+	}
+	if url.AccessCount != 3 {
+		t.Errorf("cached access count = %d, want 3", url.AccessCount)
+	}
+	if url.LastAccessed == nil || !url.LastAccessed.Equal(accessed) {
+		t.Errorf("cached last accessed = %v, want %v", url.LastAccessed, accessed)
+	}
+	if got := store.lookups.Load(); got != 1 {
+		t.Errorf("store lookups = %d, want the entry to stay cached", got)
+	}
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ClickRepository handles database operations for click events.
+type ClickRepository struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewClickRepository creates a new click repository.
+func NewClickRepository(pool *pgxpool.Pool, logger *slog.Logger) *ClickRepository {
+	return &ClickRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// insertClickQuery inserts one click event unless its URL was deleted in the meantime.
+const insertClickQuery = `
+	INSERT INTO clicks (url_id, short_code, clicked_at, referrer, user_agent, ip_address, request_id, target_rule, country, variant)
+	SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+	WHERE EXISTS (SELECT 1 FROM urls WHERE id = $1)
+`
+
+// CreateClicks inserts a batch of click events. Clicks for URLs deleted in the meantime are skipped.
+// The batch runs in one implicit transaction, so when it fails the clicks are inserted one by one and
+// only the rows the database rejects are dropped.
+func (r *ClickRepository) CreateClicks(ctx context.Context, clicks []*domain.Click) error {
+	if len(clicks) == 0 {
+		return nil
+	}
+
+	batchErr := r.insertBatch(ctx, clicks)
+	if batchErr == nil {
+		r.logger.Debug("clicks recorded", slog.Int("count", len(clicks)))
+		return nil
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("failed to insert clicks: %w", batchErr)
+	}
+
+	var recorded int
+	for _, click := range clicks {
+		if _, err := r.pool.Exec(ctx, insertClickQuery, clickArgs(click)...); err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("failed to insert clicks: %w", err)
+			}
+			r.logger.Warn("dropping click the database rejected",
+				slog.String("short_code", click.ShortCode),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		recorded++
+	}
+
+	if recorded == 0 {
+		return fmt.Errorf("failed to insert clicks: %w", batchErr)
+	}
+
+	r.logger.Debug("clicks recorded",
+		slog.Int("count", recorded),
+		slog.Int("dropped", len(clicks)-recorded),
+	)
+
+	return nil
+}
+
+// insertBatch inserts clicks in a single round trip.
+func (r *ClickRepository) insertBatch(ctx context.Context, clicks []*domain.Click) error {
+	batch := &pgx.Batch{}
+	for _, click := range clicks {
+		batch.Queue(insertClickQuery, clickArgs(click)...)
+	}
+
+	results := r.pool.SendBatch(ctx, batch)
+	for range clicks {
+		if _, err := results.Exec(); err != nil {
+			results.Close()
+			return err
+		}
+	}
+
+	return results.Close()
+}
+
+// clickArgs returns the parameters of insertClickQuery for click.
+func clickArgs(click *domain.Click) []any {
+	return []any{
+		click.URLID,
+		click.ShortCode,
+		click.ClickedAt,
+		click.Referrer,
+		click.UserAgent,
+		click.IPAddress,
+		click.RequestID,
+		click.TargetRule,
+		click.Country,
+		click.Variant,
+	}
+}
+
+// ClickStats aggregates the clicks of a URL within the query's time range.
+// TopUserAgents holds up to query.TopN entries so callers can derive browser families from them.
+func (r *ClickRepository) ClickStats(ctx context.Context, query domain.ClickStatsQuery) (*domain.ClickStats, error) {
+	stats := &domain.ClickStats{
+		From:     query.From,
+		To:       query.To,
+		Interval: query.Interval,
+	}
+
+	totalsQuery := `
+		SELECT COUNT(*), COUNT(DISTINCT ip_address || '|' || user_agent)
+		FROM clicks
+		WHERE url_id = $1 AND clicked_at >= $2 AND clicked_at < $3
+	`
+
+	err := r.pool.QueryRow(ctx, totalsQuery, query.URLID, query.From, query.To).
+		Scan(&stats.TotalClicks, &stats.UniqueVisitors)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count clicks: %w", err)
+	}
+
+	seriesQuery := `
+		SELECT date_trunc($4, clicked_at AT TIME ZONE 'UTC') AS bucket,
+		       COUNT(*),
+		       COUNT(DISTINCT ip_address || '|' || user_agent)
+		FROM clicks
+		WHERE url_id = $1 AND clicked_at >= $2 AND clicked_at < $3
+		GROUP BY bucket
+		ORDER BY bucket
+	`
+
+	rows, err := r.pool.Query(ctx, seriesQuery, query.URLID, query.From, query.To, string(query.Interval))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query click series: %w", err)
+	}
+
+	stats.Series, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.ClickBucket, error) {
+		var bucket domain.ClickBucket
+		err := row.Scan(&bucket.Start, &bucket.Clicks, &bucket.UniqueVisitors)
+		bucket.Start = bucket.Start.UTC()
+		return bucket, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan click series: %w", err)
+	}
+
+	if stats.TopReferrers, err = r.topValues(ctx, "referrer", query); err != nil {
+		return nil, err
+	}
+
+	if stats.TopUserAgents, err = r.topValues(ctx, "user_agent", query); err != nil {
+		return nil, err
+	}
+
+	if stats.TopTargetingRules, err = r.topValues(ctx, "target_rule", query); err != nil {
+		return nil, err
+	}
+
+	if stats.TopCountries, err = r.topValues(ctx, "country", query); err != nil {
+		return nil, err
+	}
+
+	if stats.Variants, err = r.topValues(ctx, "variant", query); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// topValues counts the most frequent non-empty values of a trusted column name.
+func (r *ClickRepository) topValues(ctx context.Context, column string, query domain.ClickStatsQuery) ([]domain.CountEntry, error) {
+	statement := fmt.Sprintf(`
+		SELECT %[1]s, COUNT(*)
+		FROM clicks
+		WHERE url_id = $1 AND clicked_at >= $2 AND clicked_at < $3 AND %[1]s <> ''
+		GROUP BY %[1]s
+		ORDER BY COUNT(*) DESC, %[1]s
+		LIMIT $4
+	`, column)
+
+	rows, err := r.pool.Query(ctx, statement, query.URLID, query.From, query.To, query.TopN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top %s values: %w", column, err)
+	}
+
+	entries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.CountEntry, error) {
+		var entry domain.CountEntry
+		err := row.Scan(&entry.Value, &entry.Count)
+		return entry, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan top %s values: %w", column, err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// IdempotencyRepository handles database operations for idempotency keys.
+type IdempotencyRepository struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewIdempotencyRepository creates a new idempotency key repository.
+func NewIdempotencyRepository(pool *pgxpool.Pool, logger *slog.Logger) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// CreateIdempotencyKey reserves an idempotency key for an in-progress request.
+func (r *IdempotencyRepository) CreateIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord, staleBefore time.Time) error {
+	query := `
+		INSERT INTO idempotency_keys (api_key_id, key, request_hash, status_code, response_body, created_at, expires_at)
+		VALUES ($6, $1, $2, 0, NULL, $3, $4)
+		ON CONFLICT (api_key_id, key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+		    status_code = 0,
+		    response_body = NULL,
+		    created_at = EXCLUDED.created_at,
+		    expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+		   OR (idempotency_keys.status_code = 0 AND idempotency_keys.created_at < $5)
+	`
+
+	result, err := r.pool.Exec(ctx, query, record.Key, record.RequestHash, record.CreatedAt, record.ExpiresAt, staleBefore, record.APIKeyID)
+	if err != nil {
+		return fmt.Errorf("failed to create idempotency key: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrIdempotencyKeyExists
+	}
+
+	return nil
+}
+
+// GetIdempotencyKey retrieves the record stored for an idempotency key.
+func (r *IdempotencyRepository) GetIdempotencyKey(ctx context.Context, apiKeyID int64, key string) (*domain.IdempotencyRecord, error) {
+	query := `
+		SELECT api_key_id, key, request_hash, status_code, response_body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE api_key_id = $1 AND key = $2
+	`
+
+	var record domain.IdempotencyRecord
+	err := r.pool.QueryRow(ctx, query, apiKeyID, key).Scan(
+		&record.APIKeyID,
+		&record.Key,
+		&record.RequestHash,
+		&record.StatusCode,
+		&record.ResponseBody,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrIdempotencyKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	return &record, nil
+}
+
+// CompleteIdempotencyKey stores the response of the request that reserved an idempotency key.
+func (r *IdempotencyRepository) CompleteIdempotencyKey(ctx context.Context, apiKeyID int64, key string, statusCode int, body []byte) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $1, response_body = $2
+		WHERE api_key_id = $3 AND key = $4
+	`
+
+	result, err := r.pool.Exec(ctx, query, statusCode, body, apiKeyID, key)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrIdempotencyKeyNotFound
+	}
+
+	return nil
+}
+
+// DeleteIdempotencyKey deletes an idempotency key.
+func (r *IdempotencyRepository) DeleteIdempotencyKey(ctx context.Context, apiKeyID int64, key string) error {
+	query := `DELETE FROM idempotency_keys WHERE api_key_id = $1 AND key = $2`
+
+	if _, err := r.pool.Exec(ctx, query, apiKeyID, key); err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys deletes all expired idempotency keys.
+func (r *IdempotencyRepository) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	query := `DELETE FROM idempotency_keys WHERE expires_at < $1`
+
+	result, err := r.pool.Exec(ctx, query, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	count := result.RowsAffected()
+	if count > 0 {
+		r.logger.Info("expired idempotency keys deleted", slog.Int64("count", count))
+	}
+
+	return count, nil
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+)
+
+// MemoryAPIKeyRepository is an in-memory APIKeyStore intended for local development and tests.
+type MemoryAPIKeyRepository struct {
+	mu     sync.RWMutex
+	keys   map[int64]*domain.APIKey
+	lastID int64
+	logger *slog.Logger
+}
+
+var _ APIKeyStore = (*MemoryAPIKeyRepository)(nil)
+
+// NewMemoryAPIKeyRepository creates a new in-memory API key repository.
+func NewMemoryAPIKeyRepository(logger *slog.Logger) *MemoryAPIKeyRepository {
+	return &MemoryAPIKeyRepository{
+		keys:   make(map[int64]*domain.APIKey),
+		logger: logger,
+	}
+}
+
+// CreateAPIKey stores a new API key.
+func (r *MemoryAPIKeyRepository) CreateAPIKey(ctx context.Context, key *domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	key.ID = r.lastID
+	c := *key
+	r.keys[key.ID] = &c
+
+	r.logger.Debug("api key created", slog.Int64("id", key.ID), slog.String("prefix", key.Prefix))
+
+	return nil
+}
+
+// GetAPIKey retrieves an API key by ID.
+func (r *MemoryAPIKeyRepository) GetAPIKey(ctx context.Context, id int64) (*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+
+	c := *key
+	return &c, nil
+}
+
+// GetAPIKeyByHash retrieves an API key by the hash of its secret.
+func (r *MemoryAPIKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.keys {
+		if key.KeyHash == keyHash {
+			c := *key
+			return &c, nil
+		}
+	}
+
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+// ListAPIKeys retrieves all API keys ordered by ID.
+func (r *MemoryAPIKeyRepository) ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*domain.APIKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		c := *key
+		keys = append(keys, &c)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].ID < keys[j].ID
+	})
+
+	return keys, nil
+}
+
+// RenameAPIKey changes the name of an API key.
+func (r *MemoryAPIKeyRepository) RenameAPIKey(ctx context.Context, id int64, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	key.Name = name
+
+	return nil
+}
+
+// RevokeAPIKey marks an API key as revoked.
+func (r *MemoryAPIKeyRepository) RevokeAPIKey(ctx context.Context, id int64, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	if key.RevokedAt == nil {
+		key.RevokedAt = &revokedAt
+	}
+
+	r.logger.Debug("api key revoked", slog.Int64("id", id))
+
+	return nil
+}
+
+// TouchAPIKey records when an API key was last used.
+func (r *MemoryAPIKeyRepository) TouchAPIKey(ctx context.Context, id int64, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if key, ok := r.keys[id]; ok {
+		key.LastUsedAt = &usedAt
+	}
+
+	return nil
+}
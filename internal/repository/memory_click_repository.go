@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+)
+
+// MemoryClickRepository is an in-memory ClickStore intended for local development and tests.
+type MemoryClickRepository struct {
+	mu     sync.RWMutex
+	clicks []domain.Click
+	nextID int64
+	logger *slog.Logger
+}
+
+var _ ClickStore = (*MemoryClickRepository)(nil)
+
+// NewMemoryClickRepository creates a new in-memory click repository.
+func NewMemoryClickRepository(logger *slog.Logger) *MemoryClickRepository {
+	return &MemoryClickRepository{
+		logger: logger,
+	}
+}
+
+// CreateClicks stores a batch of click events.
+func (r *MemoryClickRepository) CreateClicks(ctx context.Context, clicks []*domain.Click) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, click := range clicks {
+		r.nextID++
+		click.ID = r.nextID
+		r.clicks = append(r.clicks, *click)
+	}
+
+	r.logger.Debug("clicks recorded", slog.Int("count", len(clicks)))
+
+	return nil
+}
+
+// ClickStats aggregates the clicks of a URL within the query's time range.
+func (r *MemoryClickRepository) ClickStats(ctx context.Context, query domain.ClickStatsQuery) (*domain.ClickStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := &domain.ClickStats{
+		From:     query.From,
+		To:       query.To,
+		Interval: query.Interval,
+	}
+
+	visitors := make(map[string]struct{})
+	buckets := make(map[time.Time]*domain.ClickBucket)
+	bucketVisitors := make(map[time.Time]map[string]struct{})
+	referrers := make(map[string]int64)
+	userAgents := make(map[string]int64)
+	targetRules := make(map[string]int64)
+	countries := make(map[string]int64)
+	variants := make(map[string]int64)
+
+	for _, click := range r.clicks {
+		if click.URLID != query.URLID || click.ClickedAt.Before(query.From) || !click.ClickedAt.Before(query.To) {
+			continue
+		}
+
+		visitor := click.IPAddress + "|" + click.UserAgent
+		start := query.Interval.Truncate(click.ClickedAt)
+
+		bucket, ok := buckets[start]
+		if !ok {
+			bucket = &domain.ClickBucket{Start: start}
+			buckets[start] = bucket
+			bucketVisitors[start] = make(map[string]struct{})
+		}
+		bucket.Clicks++
+		bucketVisitors[start][visitor] = struct{}{}
+
+		stats.TotalClicks++
+		visitors[visitor] = struct{}{}
+
+		if click.Referrer != "" {
+			referrers[click.Referrer]++
+		}
+		if click.UserAgent != "" {
+			userAgents[click.UserAgent]++
+		}
+		if click.TargetRule != "" {
+			targetRules[click.TargetRule]++
+		}
+		if click.Country != "" {
+			countries[click.Country]++
+		}
+		if click.Variant != "" {
+			variants[click.Variant]++
+		}
+	}
+
+	stats.UniqueVisitors = int64(len(visitors))
+
+	for start, bucket := range buckets {
+		bucket.UniqueVisitors = int64(len(bucketVisitors[start]))
+		stats.Series = append(stats.Series, *bucket)
+	}
+	sort.Slice(stats.Series, func(i, j int) bool {
+		return stats.Series[i].Start.Before(stats.Series[j].Start)
+	})
+
+	stats.TopReferrers = topEntries(referrers, query.TopN)
+	stats.TopUserAgents = topEntries(userAgents, query.TopN)
+	stats.TopTargetingRules = topEntries(targetRules, query.TopN)
+	stats.TopCountries = topEntries(countries, query.TopN)
+	stats.Variants = topEntries(variants, query.TopN)
+
+	return stats, nil
+}
+
+func topEntries(counts map[string]int64, limit int) []domain.CountEntry {
+	entries := make([]domain.CountEntry, 0, len(counts))
+	for value, count := range counts {
+		entries = append(entries, domain.CountEntry{Value: value, Count: count})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count == entries[j].Count {
+			return entries[i].Value < entries[j].Value
+		}
+		return entries[i].Count > entries[j].Count
+	})
+
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	return entries
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+)
+
+// MemoryIdempotencyRepository is an in-memory IdempotencyStore intended for local development and tests.
+type MemoryIdempotencyRepository struct {
+	mu      sync.Mutex
+	records map[idempotencyScope]*domain.IdempotencyRecord
+	logger  *slog.Logger
+}
+
+var _ IdempotencyStore = (*MemoryIdempotencyRepository)(nil)
+
+// idempotencyScope identifies an idempotency key of one API key.
+type idempotencyScope struct {
+	apiKeyID int64
+	key      string
+}
+
+// NewMemoryIdempotencyRepository creates a new in-memory idempotency key repository.
+func NewMemoryIdempotencyRepository(logger *slog.Logger) *MemoryIdempotencyRepository {
+	return &MemoryIdempotencyRepository{
+		records: make(map[idempotencyScope]*domain.IdempotencyRecord),
+		logger:  logger,
+	}
+}
+
+// CreateIdempotencyKey reserves an idempotency key for an in-progress request.
+func (r *MemoryIdempotencyRepository) CreateIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord, staleBefore time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	scope := idempotencyScope{apiKeyID: record.APIKeyID, key: record.Key}
+	if existing, ok := r.records[scope]; ok {
+		expired := !existing.ExpiresAt.After(record.CreatedAt)
+		stale := !existing.Completed() && existing.CreatedAt.Before(staleBefore)
+		if !expired && !stale {
+			return domain.ErrIdempotencyKeyExists
+		}
+	}
+
+	r.records[scope] = &domain.IdempotencyRecord{
+		APIKeyID:    record.APIKeyID,
+		Key:         record.Key,
+		RequestHash: record.RequestHash,
+		CreatedAt:   record.CreatedAt,
+		ExpiresAt:   record.ExpiresAt,
+	}
+
+	return nil
+}
+
+// GetIdempotencyKey retrieves the record stored for an idempotency key.
+func (r *MemoryIdempotencyRepository) GetIdempotencyKey(ctx context.Context, apiKeyID int64, key string) (*domain.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.records[idempotencyScope{apiKeyID: apiKeyID, key: key}]
+	if !ok {
+		return nil, domain.ErrIdempotencyKeyNotFound
+	}
+
+	c := *record
+	c.ResponseBody = append([]byte(nil), record.ResponseBody...)
+	return &c, nil
+}
+
+// CompleteIdempotencyKey stores the response of the request that reserved an idempotency key.
+func (r *MemoryIdempotencyRepository) CompleteIdempotencyKey(ctx context.Context, apiKeyID int64, key string, statusCode int, body []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.records[idempotencyScope{apiKeyID: apiKeyID, key: key}]
+	if !ok {
+		return domain.ErrIdempotencyKeyNotFound
+	}
+
+	record.StatusCode = statusCode
+	record.ResponseBody = append([]byte(nil), body...)
+
+	return nil
+}
+
+// DeleteIdempotencyKey deletes an idempotency key.
+func (r *MemoryIdempotencyRepository) DeleteIdempotencyKey(ctx context.Context, apiKeyID int64, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.records, idempotencyScope{apiKeyID: apiKeyID, key: key})
+
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys deletes all expired idempotency keys.
+func (r *MemoryIdempotencyRepository) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var count int64
+	for scope, record := range r.records {
+		if record.ExpiresAt.Before(now) {
+			delete(r.records, scope)
+			count++
+		}
+	}
+
+	if count > 0 {
+		r.logger.Info("expired idempotency keys deleted", slog.Int64("count", count))
+	}
+
+	return count, nil
+}
@@ -0,0 +1,437 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+)
+
+// MemoryURLRepository is an in-memory URLStore intended for local development and tests.
+type MemoryURLRepository struct {
+	mu        sync.RWMutex
+	byCode    map[string]*domain.URL
+	revisions map[int64][]*domain.URLRevision
+	nextID    int64
+	logger    *slog.Logger
+}
+
+var _ URLStore = (*MemoryURLRepository)(nil)
+
+// NewMemoryURLRepository creates a new in-memory URL repository.
+func NewMemoryURLRepository(logger *slog.Logger) *MemoryURLRepository {
+	return &MemoryURLRepository{
+		byCode:    make(map[string]*domain.URL),
+		revisions: make(map[int64][]*domain.URLRevision),
+		logger:    logger,
+	}
+}
+
+// NextID reserves a row ID.
+func (r *MemoryURLRepository) NextID(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	return r.nextID, nil
+}
+
+// Create stores a new shortened URL and records its first revision.
+func (r *MemoryURLRepository) Create(ctx context.Context, url *domain.URL, change domain.Change) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.byCode[url.ShortCode]; exists {
+		return domain.ErrShortCodeAlreadyExists
+	}
+
+	if url.Version == 0 {
+		url.Version = 1
+	}
+
+	if url.ID == 0 {
+		r.nextID++
+		url.ID = r.nextID
+	} else {
+		for _, existing := range r.byCode {
+			if existing.ID == url.ID {
+				return domain.ErrShortCodeAlreadyExists
+			}
+		}
+		r.nextID = max(r.nextID, url.ID)
+	}
+	r.byCode[url.ShortCode] = cloneURL(url)
+	r.addRevision(url, change, url.CreatedAt)
+
+	r.logger.Debug("url created",
+		slog.Int64("id", url.ID),
+		slog.String("short_code", url.ShortCode),
+	)
+
+	return nil
+}
+
+// GetByShortCode retrieves a URL by its short code.
+func (r *MemoryURLRepository) GetByShortCode(ctx context.Context, shortCode string) (*domain.URL, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	url, ok := r.byCode[shortCode]
+	if !ok || url.IsDeleted() {
+		return nil, domain.ErrURLNotFound
+	}
+
+	return cloneURL(url), nil
+}
+
+// GetDeleted retrieves a soft-deleted URL by its short code.
+func (r *MemoryURLRepository) GetDeleted(ctx context.Context, shortCode string) (*domain.URL, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	url, ok := r.byCode[shortCode]
+	if !ok || !url.IsDeleted() {
+		return nil, domain.ErrURLNotFound
+	}
+
+	return cloneURL(url), nil
+}
+
+// GetByID retrieves a URL by its ID, including soft-deleted URLs.
+func (r *MemoryURLRepository) GetByID(ctx context.Context, id int64) (*domain.URL, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, url := range r.byCode {
+		if url.ID == id {
+			return cloneURL(url), nil
+		}
+	}
+
+	return nil, domain.ErrURLNotFound
+}
+
+// FindByNormalizedURL returns the most recently created unexpired URL with the given normalized destination.
+func (r *MemoryURLRepository) FindByNormalizedURL(ctx context.Context, normalizedURL string) (*domain.URL, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var found *domain.URL
+	for _, url := range r.byCode {
+		if url.NormalizedURL != normalizedURL || url.IsExpired() || url.IsDeleted() {
+			continue
+		}
+		if found == nil || url.CreatedAt.After(found.CreatedAt) ||
+			(url.CreatedAt.Equal(found.CreatedAt) && url.ID > found.ID) {
+			found = url
+		}
+	}
+
+	if found == nil {
+		return nil, domain.ErrURLNotFound
+	}
+
+	return cloneURL(found), nil
+}
+
+// Update updates the access statistics of an existing URL.
+func (r *MemoryURLRepository) Update(ctx context.Context, url *domain.URL) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.byCode[url.ShortCode]
+	if !ok || stored.ID != url.ID {
+		return domain.ErrURLNotFound
+	}
+
+	stored.AccessCount = url.AccessCount
+	stored.LastAccessed = cloneTime(url.LastAccessed)
+
+	r.logger.Debug("url updated",
+		slog.Int64("id", url.ID),
+		slog.Int64("access_count", url.AccessCount),
+	)
+
+	return nil
+}
+
+// UpdateAttributes overwrites the editable attributes of a URL if its version is unchanged
+// and records the change in the URL's history.
+func (r *MemoryURLRepository) UpdateAttributes(ctx context.Context, url *domain.URL, change domain.Change) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.byCode[url.ShortCode]
+	if !ok || stored.ID != url.ID {
+		return domain.ErrURLNotFound
+	}
+
+	if stored.Version != url.Version {
+		return domain.ErrVersionMismatch
+	}
+
+	stored.OriginalURL = url.OriginalURL
+	stored.NormalizedURL = url.NormalizedURL
+	stored.ExpiresAt = cloneTime(url.ExpiresAt)
+	stored.RedirectType = url.RedirectType
+	stored.QueryPassthrough = url.QueryPassthrough
+	stored.QueryPrecedence = url.QueryPrecedence
+	stored.PathPassthrough = url.PathPassthrough
+	stored.BaseURL = url.BaseURL
+	stored.UTM = cloneUTM(url.UTM)
+	stored.TargetingRules = slices.Clone(url.TargetingRules)
+	stored.Variants = slices.Clone(url.Variants)
+	stored.StickyVariants = url.StickyVariants
+	stored.PasswordHash = url.PasswordHash
+	stored.MaxClicks = cloneInt64(url.MaxClicks)
+	stored.ActivatesAt = cloneTime(url.ActivatesAt)
+	stored.DisabledAt = cloneTime(url.DisabledAt)
+	stored.DeletedAt = cloneTime(url.DeletedAt)
+	stored.AlwaysPreview = url.AlwaysPreview
+	stored.FallbackURL = url.FallbackURL
+	stored.Version++
+	url.Version = stored.Version
+	r.addRevision(url, change, time.Now())
+
+	r.logger.Debug("url attributes updated",
+		slog.Int64("id", url.ID),
+		slog.Int64("version", url.Version),
+	)
+
+	return nil
+}
+
+// PurgeDeleted permanently deletes URLs soft-deleted before deletedBefore.
+func (r *MemoryURLRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var count int64
+	for code, url := range r.byCode {
+		if url.DeletedAt != nil && url.DeletedAt.Before(deletedBefore) {
+			delete(r.byCode, code)
+			delete(r.revisions, url.ID)
+			count++
+		}
+	}
+
+	if count > 0 {
+		r.logger.Info("deleted urls purged", slog.Int64("count", count))
+	}
+
+	return count, nil
+}
+
+// ConsumeClick counts a redirect against the click limit of a URL.
+func (r *MemoryURLRepository) ConsumeClick(ctx context.Context, url *domain.URL) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.byCode[url.ShortCode]
+	if !ok || stored.ID != url.ID {
+		return domain.ErrURLNotFound
+	}
+
+	if stored.IsExhausted() {
+		return domain.ErrClickLimitReached
+	}
+
+	stored.UsedClicks++
+	url.UsedClicks = stored.UsedClicks
+
+	return nil
+}
+
+// DeleteExpired deletes all expired and exhausted URLs without a fallback URL. Soft-deleted URLs are
+// left to PurgeDeleted.
+func (r *MemoryURLRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var count int64
+	for code, url := range r.byCode {
+		if url.FallbackURL == "" && !url.IsDeleted() && ((url.ExpiresAt != nil && url.ExpiresAt.Before(now)) || url.IsExhausted()) {
+			delete(r.byCode, code)
+			delete(r.revisions, url.ID)
+			count++
+		}
+	}
+
+	if count > 0 {
+		r.logger.Info("expired urls deleted", slog.Int64("count", count))
+	}
+
+	return count, nil
+}
+
+// List retrieves a paginated list of URLs ordered by creation time, newest first.
+func (r *MemoryURLRepository) List(ctx context.Context, limit, offset int) ([]*domain.URL, error) {
+	r.mu.RLock()
+	all := make([]*domain.URL, 0, len(r.byCode))
+	for _, url := range r.byCode {
+		if !url.IsDeleted() {
+			all = append(all, cloneURL(url))
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].CreatedAt.Equal(all[j].CreatedAt) {
+			return all[i].ID > all[j].ID
+		}
+		return all[i].CreatedAt.After(all[j].CreatedAt)
+	})
+
+	if offset >= len(all) {
+		return nil, nil
+	}
+
+	end := offset + limit
+	if end > len(all) {
+		end = len(all)
+	}
+
+	return all[offset:end], nil
+}
+
+// Count returns the number of stored URLs that have not been deleted.
+func (r *MemoryURLRepository) Count(ctx context.Context) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, url := range r.byCode {
+		if !url.IsDeleted() {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// IncrementAccessCounts adds buffered access counts to their URLs.
+func (r *MemoryURLRepository) IncrementAccessCounts(ctx context.Context, deltas map[string]AccessDelta) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for shortCode, delta := range deltas {
+		url, ok := r.byCode[shortCode]
+		if !ok {
+			continue
+		}
+
+		url.AccessCount += delta.Count
+		if url.LastAccessed == nil || delta.LastAccessed.After(*url.LastAccessed) {
+			lastAccessed := delta.LastAccessed
+			url.LastAccessed = &lastAccessed
+		}
+	}
+
+	return nil
+}
+
+// ListRevisions returns the history of a URL, newest first.
+func (r *MemoryURLRepository) ListRevisions(ctx context.Context, urlID int64) ([]*domain.URLRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored := r.revisions[urlID]
+	revisions := make([]*domain.URLRevision, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		revisions = append(revisions, cloneRevision(stored[i]))
+	}
+
+	return revisions, nil
+}
+
+// GetRevision retrieves one version of a URL's history.
+func (r *MemoryURLRepository) GetRevision(ctx context.Context, urlID, version int64) (*domain.URLRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, revision := range r.revisions[urlID] {
+		if revision.Version == version {
+			return cloneRevision(revision), nil
+		}
+	}
+
+	return nil, domain.ErrRevisionNotFound
+}
+
+// addRevision appends the current state of url to its history. The caller must hold r.mu.
+func (r *MemoryURLRepository) addRevision(url *domain.URL, change domain.Change, changedAt time.Time) {
+	revision := &domain.URLRevision{
+		ID:          int64(len(r.revisions[url.ID]) + 1),
+		URLID:       url.ID,
+		Version:     url.Version,
+		Action:      change.Action,
+		OriginalURL: url.OriginalURL,
+		ExpiresAt:   cloneTime(url.ExpiresAt),
+		ChangedBy:   change.Actor,
+		ChangedAt:   changedAt,
+	}
+	if change.RestoredVersion != 0 {
+		restored := change.RestoredVersion
+		revision.RestoredVersion = &restored
+	}
+
+	r.revisions[url.ID] = append(r.revisions[url.ID], revision)
+}
+
+// HealthCheck always succeeds for the in-memory store.
+func (r *MemoryURLRepository) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+func cloneURL(url *domain.URL) *domain.URL {
+	c := *url
+	c.ExpiresAt = cloneTime(url.ExpiresAt)
+	c.LastAccessed = cloneTime(url.LastAccessed)
+	c.UTM = cloneUTM(url.UTM)
+	c.TargetingRules = slices.Clone(url.TargetingRules)
+	c.Variants = slices.Clone(url.Variants)
+	c.MaxClicks = cloneInt64(url.MaxClicks)
+	c.ActivatesAt = cloneTime(url.ActivatesAt)
+	c.DisabledAt = cloneTime(url.DisabledAt)
+	c.DeletedAt = cloneTime(url.DeletedAt)
+	return &c
+}
+
+func cloneUTM(utm *domain.UTMParams) *domain.UTMParams {
+	if utm == nil {
+		return nil
+	}
+	c := *utm
+	return &c
+}
+
+func cloneRevision(revision *domain.URLRevision) *domain.URLRevision {
+	c := *revision
+	c.ExpiresAt = cloneTime(revision.ExpiresAt)
+	if revision.RestoredVersion != nil {
+		restored := *revision.RestoredVersion
+		c.RestoredVersion = &restored
+	}
+	return &c
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+func cloneInt64(n *int64) *int64 {
+	if n == nil {
+		return nil
+	}
+	c := *n
+	return &c
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+)
+
+// MemoryScheduleRepository is an in-memory ScheduleStore intended for local development and tests.
+// Unlike the database stores it cannot see URLs: it lists the changes of soft-deleted URLs as due, and
+// keeps those of purged URLs until applying them fails and drops them.
+type MemoryScheduleRepository struct {
+	mu        sync.RWMutex
+	schedules map[int64]*domain.ScheduledChange
+	lastID    int64
+	logger    *slog.Logger
+}
+
+var _ ScheduleStore = (*MemoryScheduleRepository)(nil)
+
+// NewMemoryScheduleRepository creates a new in-memory schedule repository.
+func NewMemoryScheduleRepository(logger *slog.Logger) *MemoryScheduleRepository {
+	return &MemoryScheduleRepository{
+		schedules: make(map[int64]*domain.ScheduledChange),
+		logger:    logger,
+	}
+}
+
+// CreateSchedule stores a new scheduled change.
+func (r *MemoryScheduleRepository) CreateSchedule(ctx context.Context, schedule *domain.ScheduledChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	schedule.ID = r.lastID
+	c := *schedule
+	r.schedules[schedule.ID] = &c
+
+	r.logger.Debug("scheduled change created",
+		slog.String("short_code", schedule.ShortCode),
+		slog.Int64("id", schedule.ID),
+	)
+
+	return nil
+}
+
+// ListSchedules retrieves the pending changes of a URL ordered by the time they are due.
+func (r *MemoryScheduleRepository) ListSchedules(ctx context.Context, urlID int64) ([]*domain.ScheduledChange, error) {
+	return r.list(func(schedule *domain.ScheduledChange) bool {
+		return schedule.URLID == urlID
+	}, 0), nil
+}
+
+// ListDueSchedules retrieves up to limit changes due at now, oldest first.
+func (r *MemoryScheduleRepository) ListDueSchedules(ctx context.Context, now time.Time, limit int) ([]*domain.ScheduledChange, error) {
+	return r.list(func(schedule *domain.ScheduledChange) bool {
+		return !schedule.ApplyAt.After(now)
+	}, limit), nil
+}
+
+// DeleteSchedule deletes a scheduled change of a URL.
+func (r *MemoryScheduleRepository) DeleteSchedule(ctx context.Context, urlID, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	schedule, ok := r.schedules[id]
+	if !ok || schedule.URLID != urlID {
+		return domain.ErrScheduleNotFound
+	}
+
+	delete(r.schedules, id)
+
+	return nil
+}
+
+// list returns copies of the changes matching keep in the order they are applied, at most limit
+// of them unless limit is 0.
+func (r *MemoryScheduleRepository) list(keep func(*domain.ScheduledChange) bool, limit int) []*domain.ScheduledChange {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var schedules []*domain.ScheduledChange
+	for _, schedule := range r.schedules {
+		if keep(schedule) {
+			c := *schedule
+			schedules = append(schedules, &c)
+		}
+	}
+
+	sort.Slice(schedules, func(i, j int) bool {
+		if !schedules[i].ApplyAt.Equal(schedules[j].ApplyAt) {
+			return schedules[i].ApplyAt.Before(schedules[j].ApplyAt)
+		}
+		return schedules[i].ID < schedules[j].ID
+	})
+
+	if limit > 0 && len(schedules) > limit {
+		schedules = schedules[:limit]
+	}
+
+	return schedules
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+)
+
+// MemoryUTMTemplateRepository is an in-memory UTMTemplateStore intended for local development and tests.
+type MemoryUTMTemplateRepository struct {
+	mu        sync.RWMutex
+	templates map[string]*domain.UTMTemplate
+	lastID    int64
+	logger    *slog.Logger
+}
+
+var _ UTMTemplateStore = (*MemoryUTMTemplateRepository)(nil)
+
+// NewMemoryUTMTemplateRepository creates a new in-memory UTM template repository.
+func NewMemoryUTMTemplateRepository(logger *slog.Logger) *MemoryUTMTemplateRepository {
+	return &MemoryUTMTemplateRepository{
+		templates: make(map[string]*domain.UTMTemplate),
+		logger:    logger,
+	}
+}
+
+// CreateUTMTemplate creates a new UTM template.
+func (r *MemoryUTMTemplateRepository) CreateUTMTemplate(ctx context.Context, template *domain.UTMTemplate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.templates[template.Name]; exists {
+		return domain.ErrUTMTemplateExists
+	}
+
+	r.lastID++
+	template.ID = r.lastID
+	c := *template
+	r.templates[template.Name] = &c
+
+	r.logger.Debug("utm template created", slog.String("name", template.Name))
+
+	return nil
+}
+
+// GetUTMTemplate retrieves a UTM template by name.
+func (r *MemoryUTMTemplateRepository) GetUTMTemplate(ctx context.Context, name string) (*domain.UTMTemplate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	template, ok := r.templates[name]
+	if !ok {
+		return nil, domain.ErrUTMTemplateNotFound
+	}
+
+	c := *template
+	return &c, nil
+}
+
+// ListUTMTemplates retrieves all UTM templates ordered by name.
+func (r *MemoryUTMTemplateRepository) ListUTMTemplates(ctx context.Context) ([]*domain.UTMTemplate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	templates := make([]*domain.UTMTemplate, 0, len(r.templates))
+	for _, template := range r.templates {
+		c := *template
+		templates = append(templates, &c)
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+
+	return templates, nil
+}
+
+// UpdateUTMTemplate overwrites the parameters of an existing UTM template.
+func (r *MemoryUTMTemplateRepository) UpdateUTMTemplate(ctx context.Context, template *domain.UTMTemplate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.templates[template.Name]
+	if !ok {
+		return domain.ErrUTMTemplateNotFound
+	}
+
+	stored.UTMParams = template.UTMParams
+	stored.UpdatedAt = template.UpdatedAt
+	template.ID = stored.ID
+	template.CreatedAt = stored.CreatedAt
+
+	return nil
+}
+
+// DeleteUTMTemplate deletes a UTM template by name.
+func (r *MemoryUTMTemplateRepository) DeleteUTMTemplate(ctx context.Context, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.templates[name]; !ok {
+		return domain.ErrUTMTemplateNotFound
+	}
+
+	delete(r.templates, name)
+
+	r.logger.Debug("utm template deleted", slog.String("name", name))
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ScheduleRepository handles database operations for scheduled URL changes.
+type ScheduleRepository struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewScheduleRepository creates a new schedule repository.
+func NewScheduleRepository(pool *pgxpool.Pool, logger *slog.Logger) *ScheduleRepository {
+	return &ScheduleRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// CreateSchedule stores a new scheduled change.
+func (r *ScheduleRepository) CreateSchedule(ctx context.Context, schedule *domain.ScheduledChange) error {
+	query := `
+		INSERT INTO url_schedules (url_id, original_url, apply_at, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	err := r.pool.QueryRow(ctx, query,
+		schedule.URLID,
+		schedule.OriginalURL,
+		schedule.ApplyAt,
+		schedule.CreatedBy,
+		schedule.CreatedAt,
+	).Scan(&schedule.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create scheduled change: %w", err)
+	}
+
+	r.logger.Debug("scheduled change created",
+		slog.String("short_code", schedule.ShortCode),
+		slog.Int64("id", schedule.ID),
+	)
+
+	return nil
+}
+
+// ListSchedules retrieves the pending changes of a URL ordered by the time they are due.
+func (r *ScheduleRepository) ListSchedules(ctx context.Context, urlID int64) ([]*domain.ScheduledChange, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
+		FROM url_schedules s JOIN urls u ON u.id = s.url_id
+		WHERE s.url_id = $1
+		ORDER BY s.apply_at, s.id
+	`
+
+	rows, err := r.pool.Query(ctx, query, urlID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled changes: %w", err)
+	}
+
+	return collectSchedules(rows)
+}
+
+// ListDueSchedules retrieves up to limit changes due at now, oldest first.
+func (r *ScheduleRepository) ListDueSchedules(ctx context.Context, now time.Time, limit int) ([]*domain.ScheduledChange, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
+		FROM url_schedules s JOIN urls u ON u.id = s.url_id
+		WHERE s.apply_at <= $1 AND u.deleted_at IS NULL
+		ORDER BY s.apply_at, s.id
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due scheduled changes: %w", err)
+	}
+
+	return collectSchedules(rows)
+}
+
+// DeleteSchedule deletes a scheduled change of a URL.
+func (r *ScheduleRepository) DeleteSchedule(ctx context.Context, urlID, id int64) error {
+	query := `DELETE FROM url_schedules WHERE url_id = $1 AND id = $2`
+
+	result, err := r.pool.Exec(ctx, query, urlID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete scheduled change: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrScheduleNotFound
+	}
+
+	return nil
+}
+
+func collectSchedules(rows pgx.Rows) ([]*domain.ScheduledChange, error) {
+	defer rows.Close()
+
+	var schedules []*domain.ScheduledChange
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled change: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating scheduled changes: %w", err)
+	}
+
+	return schedules, nil
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+)
+
+// SQLiteAPIKeyRepository handles API key persistence in an embedded SQLite database.
+type SQLiteAPIKeyRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+var _ APIKeyStore = (*SQLiteAPIKeyRepository)(nil)
+
+// NewSQLiteAPIKeyRepository creates a new SQLite-backed API key repository.
+func NewSQLiteAPIKeyRepository(db *sql.DB, logger *slog.Logger) *SQLiteAPIKeyRepository {
+	return &SQLiteAPIKeyRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateAPIKey stores a new API key.
+func (r *SQLiteAPIKeyRepository) CreateAPIKey(ctx context.Context, key *domain.APIKey) error {
+	query := `
+		INSERT INTO api_keys (name, prefix, key_hash, admin, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		key.Admin,
+		key.CreatedBy,
+		key.CreatedAt.UTC(),
+	).Scan(&key.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	r.logger.Debug("api key created", slog.Int64("id", key.ID), slog.String("prefix", key.Prefix))
+
+	return nil
+}
+
+// GetAPIKey retrieves an API key by ID.
+func (r *SQLiteAPIKeyRepository) GetAPIKey(ctx context.Context, id int64) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = ?`
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// GetAPIKeyByHash retrieves an API key by the hash of its secret.
+func (r *SQLiteAPIKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = ?`
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, keyHash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// ListAPIKeys retrieves all API keys ordered by ID.
+func (r *SQLiteAPIKeyRepository) ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*domain.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RenameAPIKey changes the name of an API key.
+func (r *SQLiteAPIKeyRepository) RenameAPIKey(ctx context.Context, id int64, name string) error {
+	query := `UPDATE api_keys SET name = ? WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, name, id)
+	if err != nil {
+		return fmt.Errorf("failed to rename api key: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// RevokeAPIKey marks an API key as revoked.
+func (r *SQLiteAPIKeyRepository) RevokeAPIKey(ctx context.Context, id int64, revokedAt time.Time) error {
+	query := `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, ?) WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, revokedAt.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	r.logger.Debug("api key revoked", slog.Int64("id", id))
+
+	return nil
+}
+
+// TouchAPIKey records when an API key was last used.
+func (r *SQLiteAPIKeyRepository) TouchAPIKey(ctx context.Context, id int64, usedAt time.Time) error {
+	query := `UPDATE api_keys SET last_used_at = ? WHERE id = ?`
+
+	if _, err := r.db.ExecContext(ctx, query, usedAt.UTC(), id); err != nil {
+		return fmt.Errorf("failed to update api key last use: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+)
+
+// SQLiteClickRepository handles click event persistence in an embedded SQLite database.
+type SQLiteClickRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+var _ ClickStore = (*SQLiteClickRepository)(nil)
+
+// NewSQLiteClickRepository creates a new SQLite-backed click repository.
+func NewSQLiteClickRepository(db *sql.DB, logger *slog.Logger) *SQLiteClickRepository {
+	return &SQLiteClickRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateClicks inserts a batch of click events. Clicks for URLs deleted in the meantime are skipped,
+// and a click the database rejects is dropped without losing the rest of the batch.
+func (r *SQLiteClickRepository) CreateClicks(ctx context.Context, clicks []*domain.Click) error {
+	if len(clicks) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO clicks (url_id, short_code, clicked_at, referrer, user_agent, ip_address, request_id, target_rule, country, variant)
+		SELECT ?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10
+		WHERE EXISTS (SELECT 1 FROM urls WHERE id = ?1)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare click insert: %w", err)
+	}
+	defer stmt.Close()
+
+	var (
+		recorded int
+		lastErr  error
+	)
+	for _, click := range clicks {
+		_, err := stmt.ExecContext(ctx,
+			click.URLID,
+			click.ShortCode,
+			click.ClickedAt.UTC(),
+			click.Referrer,
+			click.UserAgent,
+			click.IPAddress,
+			click.RequestID,
+			click.TargetRule,
+			click.Country,
+			click.Variant,
+		)
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("failed to insert click: %w", err)
+			}
+			// A failed statement only rolls back itself, so the transaction can go on.
+			r.logger.Warn("dropping click the database rejected",
+				slog.String("short_code", click.ShortCode),
+				slog.String("error", err.Error()),
+			)
+			lastErr = err
+			continue
+		}
+		recorded++
+	}
+
+	if recorded == 0 {
+		return fmt.Errorf("failed to insert clicks: %w", lastErr)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit clicks: %w", err)
+	}
+
+	r.logger.Debug("clicks recorded",
+		slog.Int("count", recorded),
+		slog.Int("dropped", len(clicks)-recorded),
+	)
+
+	return nil
+}
+
+// sqliteBucketExpressions truncate clicked_at to the start of a UTC bucket; weeks start on Monday.
+var sqliteBucketExpressions = map[domain.StatsInterval]string{
+	domain.IntervalHour: `strftime('%Y-%m-%d %H:00:00', clicked_at)`,
+	domain.IntervalDay:  `strftime('%Y-%m-%d 00:00:00', clicked_at)`,
+	domain.IntervalWeek: `strftime('%Y-%m-%d 00:00:00', clicked_at, 'weekday 0', '-6 days')`,
+}
+
+// ClickStats aggregates the clicks of a URL within the query's time range.
+// TopUserAgents holds up to query.TopN entries so callers can derive browser families from them.
+func (r *SQLiteClickRepository) ClickStats(ctx context.Context, query domain.ClickStatsQuery) (*domain.ClickStats, error) {
+	bucketExpr, ok := sqliteBucketExpressions[query.Interval]
+	if !ok {
+		return nil, domain.ErrInvalidStatsQuery
+	}
+
+	stats := &domain.ClickStats{
+		From:     query.From,
+		To:       query.To,
+		Interval: query.Interval,
+	}
+
+	from, to := query.From.UTC(), query.To.UTC()
+
+	totalsQuery := `
+		SELECT COUNT(*), COUNT(DISTINCT ip_address || '|' || user_agent)
+		FROM clicks
+		WHERE url_id = ? AND clicked_at >= ? AND clicked_at < ?
+	`
+
+	err := r.db.QueryRowContext(ctx, totalsQuery, query.URLID, from, to).
+		Scan(&stats.TotalClicks, &stats.UniqueVisitors)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count clicks: %w", err)
+	}
+
+	seriesQuery := fmt.Sprintf(`
+		SELECT %s AS bucket,
+		       COUNT(*),
+		       COUNT(DISTINCT ip_address || '|' || user_agent)
+		FROM clicks
+		WHERE url_id = ? AND clicked_at >= ? AND clicked_at < ?
+		GROUP BY bucket
+		ORDER BY bucket
+	`, bucketExpr)
+
+	rows, err := r.db.QueryContext(ctx, seriesQuery, query.URLID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query click series: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var start string
+		var bucket domain.ClickBucket
+		if err := rows.Scan(&start, &bucket.Clicks, &bucket.UniqueVisitors); err != nil {
+			return nil, fmt.Errorf("failed to scan click series: %w", err)
+		}
+
+		bucket.Start, err = time.Parse(time.DateTime, start)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse click bucket %q: %w", start, err)
+		}
+		stats.Series = append(stats.Series, bucket)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating click series: %w", err)
+	}
+
+	if stats.TopReferrers, err = r.topValues(ctx, "referrer", query); err != nil {
+		return nil, err
+	}
+
+	if stats.TopUserAgents, err = r.topValues(ctx, "user_agent", query); err != nil {
+		return nil, err
+	}
+
+	if stats.TopTargetingRules, err = r.topValues(ctx, "target_rule", query); err != nil {
+		return nil, err
+	}
+
+	if stats.TopCountries, err = r.topValues(ctx, "country", query); err != nil {
+		return nil, err
+	}
+
+	if stats.Variants, err = r.topValues(ctx, "variant", query); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// topValues counts the most frequent non-empty values of a trusted column name.
+func (r *SQLiteClickRepository) topValues(ctx context.Context, column string, query domain.ClickStatsQuery) ([]domain.CountEntry, error) {
+	statement := fmt.Sprintf(`
+		SELECT %[1]s, COUNT(*)
+		FROM clicks
+		WHERE url_id = ? AND clicked_at >= ? AND clicked_at < ? AND %[1]s <> ''
+		GROUP BY %[1]s
+		ORDER BY COUNT(*) DESC, %[1]s
+		LIMIT ?
+	`, column)
+
+	rows, err := r.db.QueryContext(ctx, statement, query.URLID, query.From.UTC(), query.To.UTC(), query.TopN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top %s values: %w", column, err)
+	}
+	defer rows.Close()
+
+	var entries []domain.CountEntry
+	for rows.Next() {
+		var entry domain.CountEntry
+		if err := rows.Scan(&entry.Value, &entry.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan top %s values: %w", column, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating top %s values: %w", column, err)
+	}
+
+	return entries, nil
+}
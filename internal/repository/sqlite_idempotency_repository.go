@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+)
+
+// SQLiteIdempotencyRepository handles idempotency key persistence in an embedded SQLite database.
+type SQLiteIdempotencyRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+var _ IdempotencyStore = (*SQLiteIdempotencyRepository)(nil)
+
+// NewSQLiteIdempotencyRepository creates a new SQLite-backed idempotency key repository.
+func NewSQLiteIdempotencyRepository(db *sql.DB, logger *slog.Logger) *SQLiteIdempotencyRepository {
+	return &SQLiteIdempotencyRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateIdempotencyKey reserves an idempotency key for an in-progress request.
+func (r *SQLiteIdempotencyRepository) CreateIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord, staleBefore time.Time) error {
+	query := `
+		INSERT INTO idempotency_keys (api_key_id, key, request_hash, status_code, response_body, created_at, expires_at)
+		VALUES (?6, ?1, ?2, 0, NULL, ?3, ?4)
+		ON CONFLICT (api_key_id, key) DO UPDATE
+		SET request_hash = excluded.request_hash,
+		    status_code = 0,
+		    response_body = NULL,
+		    created_at = excluded.created_at,
+		    expires_at = excluded.expires_at
+		WHERE idempotency_keys.expires_at <= excluded.created_at
+		   OR (idempotency_keys.status_code = 0 AND idempotency_keys.created_at < ?5)
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		record.Key,
+		record.RequestHash,
+		record.CreatedAt.UTC(),
+		record.ExpiresAt.UTC(),
+		staleBefore.UTC(),
+		record.APIKeyID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create idempotency key: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return domain.ErrIdempotencyKeyExists
+	}
+
+	return nil
+}
+
+// GetIdempotencyKey retrieves the record stored for an idempotency key.
+func (r *SQLiteIdempotencyRepository) GetIdempotencyKey(ctx context.Context, apiKeyID int64, key string) (*domain.IdempotencyRecord, error) {
+	query := `
+		SELECT api_key_id, key, request_hash, status_code, response_body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE api_key_id = ? AND key = ?
+	`
+
+	var record domain.IdempotencyRecord
+	err := r.db.QueryRowContext(ctx, query, apiKeyID, key).Scan(
+		&record.APIKeyID,
+		&record.Key,
+		&record.RequestHash,
+		&record.StatusCode,
+		&record.ResponseBody,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrIdempotencyKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	return &record, nil
+}
+
+// CompleteIdempotencyKey stores the response of the request that reserved an idempotency key.
+func (r *SQLiteIdempotencyRepository) CompleteIdempotencyKey(ctx context.Context, apiKeyID int64, key string, statusCode int, body []byte) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = ?, response_body = ?
+		WHERE api_key_id = ? AND key = ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query, statusCode, body, apiKeyID, key)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return domain.ErrIdempotencyKeyNotFound
+	}
+
+	return nil
+}
+
+// DeleteIdempotencyKey deletes an idempotency key.
+func (r *SQLiteIdempotencyRepository) DeleteIdempotencyKey(ctx context.Context, apiKeyID int64, key string) error {
+	query := `DELETE FROM idempotency_keys WHERE api_key_id = ? AND key = ?`
+
+	if _, err := r.db.ExecContext(ctx, query, apiKeyID, key); err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys deletes all expired idempotency keys.
+func (r *SQLiteIdempotencyRepository) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	query := `DELETE FROM idempotency_keys WHERE expires_at < ?`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read affected rows: %w", err)
+	}
+
+	if count > 0 {
+		r.logger.Info("expired idempotency keys deleted", slog.Int64("count", count))
+	}
+
+	return count, nil
+}
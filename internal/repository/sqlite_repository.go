@@ -0,0 +1,592 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// SQLiteURLRepository handles URL persistence in an embedded SQLite database.
+type SQLiteURLRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+
+	idMu   sync.Mutex
+	lastID int64
+}
+
+var _ URLStore = (*SQLiteURLRepository)(nil)
+
+// NewSQLiteURLRepository creates a new SQLite-backed URL repository.
+func NewSQLiteURLRepository(db *sql.DB, logger *slog.Logger) *SQLiteURLRepository {
+	return &SQLiteURLRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// NextID reserves a row ID. SQLite has no sequences, so IDs are handed out from an
+// in-process counter seeded from the table; this is safe because SQLite deployments are single-node.
+func (r *SQLiteURLRepository) NextID(ctx context.Context) (int64, error) {
+	r.idMu.Lock()
+	defer r.idMu.Unlock()
+
+	var maxID int64
+	query := `
+		SELECT MAX(
+			COALESCE((SELECT MAX(id) FROM urls), 0),
+			COALESCE((SELECT seq FROM sqlite_sequence WHERE name = 'urls'), 0)
+		)
+	`
+	if err := r.db.QueryRowContext(ctx, query).Scan(&maxID); err != nil {
+		return 0, fmt.Errorf("failed to reserve url id: %w", err)
+	}
+
+	r.lastID = max(r.lastID, maxID) + 1
+
+	return r.lastID, nil
+}
+
+// Create creates a new shortened URL in the database and records its first revision.
+func (r *SQLiteURLRepository) Create(ctx context.Context, url *domain.URL, change domain.Change) error {
+	query := `
+		INSERT INTO urls (
+			id, short_code, original_url, normalized_url, created_at, expires_at, access_count, redirect_type,
+			query_passthrough, query_precedence, path_passthrough,
+			base_url, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules,
+			variants, sticky_variants, password_hash, max_clicks, activates_at, always_preview, fallback_url
+		)
+		VALUES (?, ?, ?, NULLIF(?, ''), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id, version
+	`
+
+	var id any
+	if url.ID != 0 {
+		id = url.ID
+	}
+	utm := urlUTM(url)
+	rules, err := encodeJSONList(url.TargetingRules, "targeting rules")
+	if err != nil {
+		return err
+	}
+	variants, err := encodeJSONList(url.Variants, "variants")
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		id,
+		url.ShortCode,
+		url.OriginalURL,
+		url.NormalizedURL,
+		url.CreatedAt.UTC(),
+		utcOrNil(url.ExpiresAt),
+		url.AccessCount,
+		url.RedirectType,
+		url.QueryPassthrough,
+		url.QueryPrecedence,
+		url.PathPassthrough,
+		url.BaseURL,
+		utm.Source,
+		utm.Medium,
+		utm.Campaign,
+		utm.Term,
+		utm.Content,
+		rules,
+		variants,
+		url.StickyVariants,
+		url.PasswordHash,
+		url.MaxClicks,
+		utcOrNil(url.ActivatesAt),
+		url.AlwaysPreview,
+		url.FallbackURL,
+	).Scan(&url.ID, &url.Version)
+
+	if err != nil {
+		if isSQLiteUniqueViolation(err) {
+			return domain.ErrShortCodeAlreadyExists
+		}
+		return fmt.Errorf("failed to create url: %w", err)
+	}
+
+	if err := insertSQLiteRevision(ctx, tx, url, change, url.CreatedAt); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit url: %w", err)
+	}
+
+	r.logger.Debug("url created",
+		slog.Int64("id", url.ID),
+		slog.String("short_code", url.ShortCode),
+	)
+
+	return nil
+}
+
+// GetByShortCode retrieves a URL by its short code.
+func (r *SQLiteURLRepository) GetByShortCode(ctx context.Context, shortCode string) (*domain.URL, error) {
+	query := `
+		SELECT ` + urlColumns + `
+		FROM urls
+		WHERE short_code = ? AND deleted_at IS NULL
+	`
+
+	url, err := scanURL(r.db.QueryRowContext(ctx, query, shortCode))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrURLNotFound
+		}
+		return nil, fmt.Errorf("failed to get url by short code: %w", err)
+	}
+
+	return url, nil
+}
+
+// GetDeleted retrieves a soft-deleted URL by its short code.
+func (r *SQLiteURLRepository) GetDeleted(ctx context.Context, shortCode string) (*domain.URL, error) {
+	query := `
+		SELECT ` + urlColumns + `
+		FROM urls
+		WHERE short_code = ? AND deleted_at IS NOT NULL
+	`
+
+	url, err := scanURL(r.db.QueryRowContext(ctx, query, shortCode))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrURLNotFound
+		}
+		return nil, fmt.Errorf("failed to get deleted url: %w", err)
+	}
+
+	return url, nil
+}
+
+// GetByID retrieves a URL by its ID, including soft-deleted URLs.
+func (r *SQLiteURLRepository) GetByID(ctx context.Context, id int64) (*domain.URL, error) {
+	query := `
+		SELECT ` + urlColumns + `
+		FROM urls
+		WHERE id = ?
+	`
+
+	url, err := scanURL(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrURLNotFound
+		}
+		return nil, fmt.Errorf("failed to get url by id: %w", err)
+	}
+
+	return url, nil
+}
+
+// FindByNormalizedURL returns the most recently created unexpired URL with the given normalized destination.
+func (r *SQLiteURLRepository) FindByNormalizedURL(ctx context.Context, normalizedURL string) (*domain.URL, error) {
+	query := `
+		SELECT ` + urlColumns + `
+		FROM urls
+		WHERE normalized_url = ? AND (expires_at IS NULL OR expires_at > ?) AND deleted_at IS NULL
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`
+
+	url, err := scanURL(r.db.QueryRowContext(ctx, query, normalizedURL, time.Now().UTC()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrURLNotFound
+		}
+		return nil, fmt.Errorf("failed to find url by normalized url: %w", err)
+	}
+
+	url.NormalizedURL = normalizedURL
+
+	return url, nil
+}
+
+// Update updates an existing URL.
+func (r *SQLiteURLRepository) Update(ctx context.Context, url *domain.URL) error {
+	query := `
+		UPDATE urls
+		SET access_count = ?, last_accessed = ?
+		WHERE id = ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query, url.AccessCount, utcOrNil(url.LastAccessed), url.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update url: %w", err)
+	}
+
+	if err := requireRowsAffected(result); err != nil {
+		return err
+	}
+
+	r.logger.Debug("url updated",
+		slog.Int64("id", url.ID),
+		slog.Int64("access_count", url.AccessCount),
+	)
+
+	return nil
+}
+
+// UpdateAttributes overwrites the editable attributes of a URL if its version is unchanged
+// and records the change in the URL's history.
+func (r *SQLiteURLRepository) UpdateAttributes(ctx context.Context, url *domain.URL, change domain.Change) error {
+	query := `
+		UPDATE urls
+		SET original_url = ?, normalized_url = NULLIF(?, ''), expires_at = ?, redirect_type = ?,
+		    query_passthrough = ?, query_precedence = ?, path_passthrough = ?,
+		    base_url = ?, utm_source = ?, utm_medium = ?, utm_campaign = ?, utm_term = ?, utm_content = ?,
+		    targeting_rules = ?, variants = ?, sticky_variants = ?, password_hash = ?, max_clicks = ?,
+		    activates_at = ?, disabled_at = ?, deleted_at = ?, always_preview = ?, fallback_url = ?,
+		    version = version + 1
+		WHERE id = ? AND version = ?
+		RETURNING version
+	`
+	utm := urlUTM(url)
+	rules, err := encodeJSONList(url.TargetingRules, "targeting rules")
+	if err != nil {
+		return err
+	}
+	variants, err := encodeJSONList(url.Variants, "variants")
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, query,
+		url.OriginalURL,
+		url.NormalizedURL,
+		utcOrNil(url.ExpiresAt),
+		url.RedirectType,
+		url.QueryPassthrough,
+		url.QueryPrecedence,
+		url.PathPassthrough,
+		url.BaseURL,
+		utm.Source,
+		utm.Medium,
+		utm.Campaign,
+		utm.Term,
+		utm.Content,
+		rules,
+		variants,
+		url.StickyVariants,
+		url.PasswordHash,
+		url.MaxClicks,
+		utcOrNil(url.ActivatesAt),
+		utcOrNil(url.DisabledAt),
+		utcOrNil(url.DeletedAt),
+		url.AlwaysPreview,
+		url.FallbackURL,
+		url.ID,
+		url.Version,
+	).Scan(&url.Version)
+
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to update url attributes: %w", err)
+		}
+		if err := tx.QueryRowContext(ctx, `SELECT id FROM urls WHERE id = ?`, url.ID).Scan(new(int64)); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrURLNotFound
+			}
+			return fmt.Errorf("failed to get url by id: %w", err)
+		}
+		return domain.ErrVersionMismatch
+	}
+
+	if err := insertSQLiteRevision(ctx, tx, url, change, time.Now()); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit url attributes: %w", err)
+	}
+
+	r.logger.Debug("url attributes updated",
+		slog.Int64("id", url.ID),
+		slog.Int64("version", url.Version),
+	)
+
+	return nil
+}
+
+// ListRevisions returns the history of a URL, newest first.
+func (r *SQLiteURLRepository) ListRevisions(ctx context.Context, urlID int64) ([]*domain.URLRevision, error) {
+	query := `
+		SELECT ` + revisionColumns + `
+		FROM url_history
+		WHERE url_id = ?
+		ORDER BY version DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, urlID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list url revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []*domain.URLRevision
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan url revision row: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating url revision rows: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// GetRevision retrieves one version of a URL's history.
+func (r *SQLiteURLRepository) GetRevision(ctx context.Context, urlID, version int64) (*domain.URLRevision, error) {
+	query := `
+		SELECT ` + revisionColumns + `
+		FROM url_history
+		WHERE url_id = ? AND version = ?
+	`
+
+	revision, err := scanRevision(r.db.QueryRowContext(ctx, query, urlID, version))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRevisionNotFound
+		}
+		return nil, fmt.Errorf("failed to get url revision: %w", err)
+	}
+
+	return revision, nil
+}
+
+// PurgeDeleted permanently deletes URLs soft-deleted before deletedBefore.
+func (r *SQLiteURLRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	query := `DELETE FROM urls WHERE deleted_at IS NOT NULL AND deleted_at < ?`
+
+	result, err := r.db.ExecContext(ctx, query, deletedBefore.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted urls: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read affected rows: %w", err)
+	}
+
+	if count > 0 {
+		r.logger.Info("deleted urls purged", slog.Int64("count", count))
+	}
+
+	return count, nil
+}
+
+// ConsumeClick atomically counts a redirect against the click limit of a URL.
+func (r *SQLiteURLRepository) ConsumeClick(ctx context.Context, url *domain.URL) error {
+	query := `
+		UPDATE urls
+		SET used_clicks = used_clicks + 1
+		WHERE id = ? AND (max_clicks IS NULL OR used_clicks < max_clicks)
+		RETURNING used_clicks
+	`
+
+	err := r.db.QueryRowContext(ctx, query, url.ID).Scan(&url.UsedClicks)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to consume click: %w", err)
+		}
+		if _, err := r.GetByID(ctx, url.ID); err != nil {
+			return err
+		}
+		return domain.ErrClickLimitReached
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes all expired and exhausted URLs without a fallback URL. Soft-deleted URLs are
+// left to PurgeDeleted.
+func (r *SQLiteURLRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	query := `
+		DELETE FROM urls
+		WHERE fallback_url = '' AND deleted_at IS NULL
+		  AND ((expires_at IS NOT NULL AND expires_at < ?)
+		   OR (max_clicks IS NOT NULL AND used_clicks >= max_clicks))
+	`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired urls: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read affected rows: %w", err)
+	}
+
+	if count > 0 {
+		r.logger.Info("expired urls deleted", slog.Int64("count", count))
+	}
+
+	return count, nil
+}
+
+// List retrieves a paginated list of URLs.
+func (r *SQLiteURLRepository) List(ctx context.Context, limit, offset int) ([]*domain.URL, error) {
+	query := `
+		SELECT ` + urlColumns + `
+		FROM urls
+		WHERE deleted_at IS NULL
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list urls: %w", err)
+	}
+	defer rows.Close()
+
+	var urls []*domain.URL
+	for rows.Next() {
+		url, err := scanURL(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan url row: %w", err)
+		}
+		urls = append(urls, url)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating url rows: %w", err)
+	}
+
+	return urls, nil
+}
+
+// Count returns the number of URLs that have not been deleted.
+func (r *SQLiteURLRepository) Count(ctx context.Context) (int64, error) {
+	query := `SELECT COUNT(*) FROM urls WHERE deleted_at IS NULL`
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count urls: %w", err)
+	}
+
+	return count, nil
+}
+
+// IncrementAccessCounts atomically adds buffered access counts to their URLs in one transaction.
+func (r *SQLiteURLRepository) IncrementAccessCounts(ctx context.Context, deltas map[string]AccessDelta) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		UPDATE urls
+		SET access_count = access_count + ?,
+		    last_accessed = MAX(COALESCE(last_accessed, ?), ?)
+		WHERE short_code = ?
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare access count update: %w", err)
+	}
+	defer stmt.Close()
+
+	for shortCode, delta := range deltas {
+		lastAccessed := delta.LastAccessed.UTC()
+		if _, err := stmt.ExecContext(ctx, delta.Count, lastAccessed, lastAccessed, shortCode); err != nil {
+			return fmt.Errorf("failed to increment access counts: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit access counts: %w", err)
+	}
+
+	r.logger.Debug("access counts flushed", slog.Int("urls", len(deltas)))
+
+	return nil
+}
+
+func insertSQLiteRevision(ctx context.Context, tx *sql.Tx, url *domain.URL, change domain.Change, changedAt time.Time) error {
+	query := `
+		INSERT INTO url_history (url_id, version, action, original_url, expires_at, restored_version, changed_by, changed_at)
+		VALUES (?, ?, ?, ?, ?, NULLIF(?, 0), ?, ?)
+	`
+
+	_, err := tx.ExecContext(ctx, query,
+		url.ID,
+		url.Version,
+		change.Action,
+		url.OriginalURL,
+		utcOrNil(url.ExpiresAt),
+		change.RestoredVersion,
+		change.Actor,
+		changedAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record url revision: %w", err)
+	}
+
+	return nil
+}
+
+func requireRowsAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return domain.ErrURLNotFound
+	}
+
+	return nil
+}
+
+func isSQLiteUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+
+	return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE ||
+		sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
+
+// utcOrNil normalises optional timestamps so SQLite's textual comparisons stay ordered.
+func utcOrNil(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+)
+
+// SQLiteScheduleRepository handles scheduled URL change persistence in an embedded SQLite database.
+type SQLiteScheduleRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+var _ ScheduleStore = (*SQLiteScheduleRepository)(nil)
+
+// NewSQLiteScheduleRepository creates a new SQLite-backed schedule repository.
+func NewSQLiteScheduleRepository(db *sql.DB, logger *slog.Logger) *SQLiteScheduleRepository {
+	return &SQLiteScheduleRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateSchedule stores a new scheduled change.
+func (r *SQLiteScheduleRepository) CreateSchedule(ctx context.Context, schedule *domain.ScheduledChange) error {
+	query := `
+		INSERT INTO url_schedules (url_id, original_url, apply_at, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		schedule.URLID,
+		schedule.OriginalURL,
+		schedule.ApplyAt.UTC(),
+		schedule.CreatedBy,
+		schedule.CreatedAt.UTC(),
+	).Scan(&schedule.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create scheduled change: %w", err)
+	}
+
+	r.logger.Debug("scheduled change created",
+		slog.String("short_code", schedule.ShortCode),
+		slog.Int64("id", schedule.ID),
+	)
+
+	return nil
+}
+
+// ListSchedules retrieves the pending changes of a URL ordered by the time they are due.
+func (r *SQLiteScheduleRepository) ListSchedules(ctx context.Context, urlID int64) ([]*domain.ScheduledChange, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
+		FROM url_schedules s JOIN urls u ON u.id = s.url_id
+		WHERE s.url_id = ?
+		ORDER BY s.apply_at, s.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, urlID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled changes: %w", err)
+	}
+
+	return collectSQLiteSchedules(rows)
+}
+
+// ListDueSchedules retrieves up to limit changes due at now, oldest first.
+func (r *SQLiteScheduleRepository) ListDueSchedules(ctx context.Context, now time.Time, limit int) ([]*domain.ScheduledChange, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
+		FROM url_schedules s JOIN urls u ON u.id = s.url_id
+		WHERE s.apply_at <= ? AND u.deleted_at IS NULL
+		ORDER BY s.apply_at, s.id
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, now.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due scheduled changes: %w", err)
+	}
+
+	return collectSQLiteSchedules(rows)
+}
+
+// DeleteSchedule deletes a scheduled change of a URL.
+func (r *SQLiteScheduleRepository) DeleteSchedule(ctx context.Context, urlID, id int64) error {
+	query := `DELETE FROM url_schedules WHERE url_id = ? AND id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, urlID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete scheduled change: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read affected rows: %w", err)
+	}
+
+	if count == 0 {
+		return domain.ErrScheduleNotFound
+	}
+
+	return nil
+}
+
+func collectSQLiteSchedules(rows *sql.Rows) ([]*domain.ScheduledChange, error) {
+	defer rows.Close()
+
+	var schedules []*domain.ScheduledChange
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled change: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating scheduled changes: %w", err)
+	}
+
+	return schedules, nil
+}
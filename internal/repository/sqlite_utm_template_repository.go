@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+)
+
+// SQLiteUTMTemplateRepository handles UTM template persistence in an embedded SQLite database.
+type SQLiteUTMTemplateRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+var _ UTMTemplateStore = (*SQLiteUTMTemplateRepository)(nil)
+
+// NewSQLiteUTMTemplateRepository creates a new SQLite-backed UTM template repository.
+func NewSQLiteUTMTemplateRepository(db *sql.DB, logger *slog.Logger) *SQLiteUTMTemplateRepository {
+	return &SQLiteUTMTemplateRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateUTMTemplate creates a new UTM template.
+func (r *SQLiteUTMTemplateRepository) CreateUTMTemplate(ctx context.Context, template *domain.UTMTemplate) error {
+	query := `
+		INSERT INTO utm_templates (name, utm_source, utm_medium, utm_campaign, utm_term, utm_content, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		template.Name,
+		template.Source,
+		template.Medium,
+		template.Campaign,
+		template.Term,
+		template.Content,
+		template.CreatedAt.UTC(),
+		template.UpdatedAt.UTC(),
+	).Scan(&template.ID)
+
+	if err != nil {
+		if isSQLiteUniqueViolation(err) {
+			return domain.ErrUTMTemplateExists
+		}
+		return fmt.Errorf("failed to create utm template: %w", err)
+	}
+
+	r.logger.Debug("utm template created", slog.String("name", template.Name))
+
+	return nil
+}
+
+// GetUTMTemplate retrieves a UTM template by name.
+func (r *SQLiteUTMTemplateRepository) GetUTMTemplate(ctx context.Context, name string) (*domain.UTMTemplate, error) {
+	query := `SELECT ` + utmTemplateColumns + ` FROM utm_templates WHERE name = ?`
+
+	template, err := scanUTMTemplate(r.db.QueryRowContext(ctx, query, name))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUTMTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to get utm template: %w", err)
+	}
+
+	return template, nil
+}
+
+// ListUTMTemplates retrieves all UTM templates ordered by name.
+func (r *SQLiteUTMTemplateRepository) ListUTMTemplates(ctx context.Context) ([]*domain.UTMTemplate, error) {
+	query := `SELECT ` + utmTemplateColumns + ` FROM utm_templates ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list utm templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*domain.UTMTemplate
+	for rows.Next() {
+		template, err := scanUTMTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan utm template: %w", err)
+		}
+		templates = append(templates, template)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating utm templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// UpdateUTMTemplate overwrites the parameters of an existing UTM template.
+func (r *SQLiteUTMTemplateRepository) UpdateUTMTemplate(ctx context.Context, template *domain.UTMTemplate) error {
+	query := `
+		UPDATE utm_templates
+		SET utm_source = ?, utm_medium = ?, utm_campaign = ?, utm_term = ?, utm_content = ?, updated_at = ?
+		WHERE name = ?
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		template.Source,
+		template.Medium,
+		template.Campaign,
+		template.Term,
+		template.Content,
+		template.UpdatedAt.UTC(),
+		template.Name,
+	).Scan(&template.ID, &template.CreatedAt)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrUTMTemplateNotFound
+		}
+		return fmt.Errorf("failed to update utm template: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteUTMTemplate deletes a UTM template by name.
+func (r *SQLiteUTMTemplateRepository) DeleteUTMTemplate(ctx context.Context, name string) error {
+	query := `DELETE FROM utm_templates WHERE name = ?`
+
+	result, err := r.db.ExecContext(ctx, query, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete utm template: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return domain.ErrUTMTemplateNotFound
+	}
+
+	r.logger.Debug("utm template deleted", slog.String("name", name))
+
+	return nil
+}
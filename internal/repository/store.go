@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+)
+
+// URLStore defines the persistence operations required by the URL service.
+type URLStore interface {
+	// NextID reserves a row ID. Create uses url.ID when it is non-zero.
+	NextID(ctx context.Context) (int64, error)
+	// Create stores url and records change as its first revision.
+	Create(ctx context.Context, url *domain.URL, change domain.Change) error
+	GetByShortCode(ctx context.Context, shortCode string) (*domain.URL, error)
+	GetByID(ctx context.Context, id int64) (*domain.URL, error)
+	// GetDeleted returns the soft-deleted URL with the given short code. The other lookups, List and Count
+	// ignore soft-deleted URLs.
+	GetDeleted(ctx context.Context, shortCode string) (*domain.URL, error)
+	// FindByNormalizedURL returns the most recently created unexpired URL with the given normalized destination.
+	FindByNormalizedURL(ctx context.Context, normalizedURL string) (*domain.URL, error)
+	Update(ctx context.Context, url *domain.URL) error
+	// UpdateAttributes overwrites the editable attributes of url, increments its version and records change
+	// in its history, provided the stored version still equals url.Version. It returns
+	// domain.ErrVersionMismatch otherwise.
+	UpdateAttributes(ctx context.Context, url *domain.URL, change domain.Change) error
+	// ListRevisions returns the history of a URL, newest first.
+	ListRevisions(ctx context.Context, urlID int64) ([]*domain.URLRevision, error)
+	GetRevision(ctx context.Context, urlID, version int64) (*domain.URLRevision, error)
+	// PurgeDeleted permanently deletes URLs soft-deleted before deletedBefore, freeing their short codes.
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error)
+	// ConsumeClick counts a redirect against the click limit of url and updates url.UsedClicks. It
+	// returns domain.ErrClickLimitReached, without counting, when the limit has already been reached.
+	ConsumeClick(ctx context.Context, url *domain.URL) error
+	// DeleteExpired deletes URLs that have expired or used up their click limit, unless they have a
+	// fallback URL to keep sending visitors to. Soft-deleted URLs are kept until PurgeDeleted removes
+	// them, so they can still be restored.
+	DeleteExpired(ctx context.Context) (int64, error)
+	List(ctx context.Context, limit, offset int) ([]*domain.URL, error)
+	Count(ctx context.Context) (int64, error)
+	IncrementAccessCounts(ctx context.Context, deltas map[string]AccessDelta) error
+}
+
+// urlColumns lists the urls columns read by scanURL, in order.
+const urlColumns = `id, short_code, original_url, created_at, expires_at, access_count, last_accessed, version, redirect_type,
+	query_passthrough, query_precedence, path_passthrough, base_url, utm_source, utm_medium, utm_campaign, utm_term, utm_content,
+	targeting_rules, variants, sticky_variants, password_hash, max_clicks, used_clicks,
+	activates_at, disabled_at, deleted_at, always_preview, fallback_url`
+
+// revisionColumns lists the url_history columns read by scanRevision, in order.
+const revisionColumns = `id, url_id, version, action, original_url, expires_at, restored_version, changed_by, changed_at`
+
+// AccessDelta is a batch of accesses to a single short code awaiting persistence.
+type AccessDelta struct {
+	Count        int64
+	LastAccessed time.Time
+}
+
+var _ URLStore = (*URLRepository)(nil)
+
+// ClickStore defines the persistence operations for click events.
+type ClickStore interface {
+	CreateClicks(ctx context.Context, clicks []*domain.Click) error
+	ClickStats(ctx context.Context, query domain.ClickStatsQuery) (*domain.ClickStats, error)
+}
+
+var _ ClickStore = (*ClickRepository)(nil)
+
+// IdempotencyStore defines the persistence operations for idempotency keys.
+type IdempotencyStore interface {
+	// Idempotency keys are unique per API key, so every method takes the ID of the key that owns them.
+
+	// CreateIdempotencyKey reserves record.Key for record.APIKeyID, replacing an existing record only if it has expired or is an
+	// unfinished reservation created before staleBefore. It returns domain.ErrIdempotencyKeyExists otherwise.
+	CreateIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord, staleBefore time.Time) error
+	GetIdempotencyKey(ctx context.Context, apiKeyID int64, key string) (*domain.IdempotencyRecord, error)
+	CompleteIdempotencyKey(ctx context.Context, apiKeyID int64, key string, statusCode int, body []byte) error
+	DeleteIdempotencyKey(ctx context.Context, apiKeyID int64, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+}
+
+var _ IdempotencyStore = (*IdempotencyRepository)(nil)
+
+// UTMTemplateStore defines the persistence operations for UTM templates.
+type UTMTemplateStore interface {
+	// CreateUTMTemplate stores template and returns domain.ErrUTMTemplateExists if its name is taken.
+	CreateUTMTemplate(ctx context.Context, template *domain.UTMTemplate) error
+	GetUTMTemplate(ctx context.Context, name string) (*domain.UTMTemplate, error)
+	ListUTMTemplates(ctx context.Context) ([]*domain.UTMTemplate, error)
+	// UpdateUTMTemplate overwrites the parameters of the template named template.Name.
+	UpdateUTMTemplate(ctx context.Context, template *domain.UTMTemplate) error
+	DeleteUTMTemplate(ctx context.Context, name string) error
+}
+
+var _ UTMTemplateStore = (*UTMTemplateRepository)(nil)
+
+// ScheduleStore defines the persistence operations for scheduled URL changes.
+type ScheduleStore interface {
+	CreateSchedule(ctx context.Context, schedule *domain.ScheduledChange) error
+	// ListSchedules returns the pending changes of a URL in the order they are applied.
+	ListSchedules(ctx context.Context, urlID int64) ([]*domain.ScheduledChange, error)
+	// ListDueSchedules returns up to limit changes due at now, oldest first. Changes of soft-deleted URLs
+	// are not due until the URL is restored.
+	ListDueSchedules(ctx context.Context, now time.Time, limit int) ([]*domain.ScheduledChange, error)
+	// DeleteSchedule deletes the change id of a URL and returns domain.ErrScheduleNotFound if it has none.
+	DeleteSchedule(ctx context.Context, urlID, id int64) error
+}
+
+var _ ScheduleStore = (*ScheduleRepository)(nil)
+
+// APIKeyStore defines the persistence operations for API keys.
+type APIKeyStore interface {
+	CreateAPIKey(ctx context.Context, key *domain.APIKey) error
+	GetAPIKey(ctx context.Context, id int64) (*domain.APIKey, error)
+	// GetAPIKeyByHash returns the key whose secret hashes to keyHash, revoked or not.
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*domain.APIKey, error)
+	// ListAPIKeys returns every key, revoked ones included, oldest first.
+	ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error)
+	RenameAPIKey(ctx context.Context, id int64, name string) error
+	// RevokeAPIKey revokes the key id as of revokedAt. Revoking a revoked key keeps its revocation time.
+	RevokeAPIKey(ctx context.Context, id int64, revokedAt time.Time) error
+	// TouchAPIKey records that the key id was used at usedAt.
+	TouchAPIKey(ctx context.Context, id int64, usedAt time.Time) error
+}
+
+var _ APIKeyStore = (*APIKeyRepository)(nil)
+
+// scheduleColumns lists the url_schedules columns, joined with urls, read by scanSchedule, in order.
+const scheduleColumns = `s.id, s.url_id, u.short_code, s.original_url, s.apply_at, s.created_by, s.created_at`
+
+// apiKeyColumns lists the api_keys columns read by scanAPIKey, in order.
+const apiKeyColumns = `id, name, prefix, key_hash, admin, created_by, created_at, last_used_at, revoked_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanURL scans a row selected with urlColumns.
+func scanURL(row rowScanner) (*domain.URL, error) {
+	var url domain.URL
+	var utm domain.UTMParams
+	var rules, variants []byte
+	err := row.Scan(
+		&url.ID,
+		&url.ShortCode,
+		&url.OriginalURL,
+		&url.CreatedAt,
+		&url.ExpiresAt,
+		&url.AccessCount,
+		&url.LastAccessed,
+		&url.Version,
+		&url.RedirectType,
+		&url.QueryPassthrough,
+		&url.QueryPrecedence,
+		&url.PathPassthrough,
+		&url.BaseURL,
+		&utm.Source,
+		&utm.Medium,
+		&utm.Campaign,
+		&utm.Term,
+		&utm.Content,
+		&rules,
+		&variants,
+		&url.StickyVariants,
+		&url.PasswordHash,
+		&url.MaxClicks,
+		&url.UsedClicks,
+		&url.ActivatesAt,
+		&url.DisabledAt,
+		&url.DeletedAt,
+		&url.AlwaysPreview,
+		&url.FallbackURL,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if !utm.IsZero() {
+		url.UTM = &utm
+	}
+
+	if len(rules) > 0 {
+		if err := json.Unmarshal(rules, &url.TargetingRules); err != nil {
+			return nil, fmt.Errorf("failed to decode targeting rules: %w", err)
+		}
+	}
+
+	if len(variants) > 0 {
+		if err := json.Unmarshal(variants, &url.Variants); err != nil {
+			return nil, fmt.Errorf("failed to decode variants: %w", err)
+		}
+	}
+
+	return &url, nil
+}
+
+// utmTemplateColumns lists the utm_templates columns read by scanUTMTemplate, in order.
+const utmTemplateColumns = `id, name, utm_source, utm_medium, utm_campaign, utm_term, utm_content, created_at, updated_at`
+
+// urlUTM returns the UTM parameters stored for url; they are empty for links without any.
+func urlUTM(url *domain.URL) domain.UTMParams {
+	if url.UTM == nil {
+		return domain.UTMParams{}
+	}
+	return *url.UTM
+}
+
+// encodeJSONList returns the JSON stored for a list column such as targeting_rules, or nil when items is
+// empty. what names the list in errors.
+func encodeJSONList[T any](items []T, what string) ([]byte, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	encoded, err := json.Marshal(items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", what, err)
+	}
+
+	return encoded, nil
+}
+
+// scanSchedule scans a row selected with scheduleColumns.
+func scanSchedule(row rowScanner) (*domain.ScheduledChange, error) {
+	var schedule domain.ScheduledChange
+	err := row.Scan(
+		&schedule.ID,
+		&schedule.URLID,
+		&schedule.ShortCode,
+		&schedule.OriginalURL,
+		&schedule.ApplyAt,
+		&schedule.CreatedBy,
+		&schedule.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+// scanRevision scans a row selected with revisionColumns.
+func scanRevision(row rowScanner) (*domain.URLRevision, error) {
+	var revision domain.URLRevision
+	err := row.Scan(
+		&revision.ID,
+		&revision.URLID,
+		&revision.Version,
+		&revision.Action,
+		&revision.OriginalURL,
+		&revision.ExpiresAt,
+		&revision.RestoredVersion,
+		&revision.ChangedBy,
+		&revision.ChangedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &revision, nil
+}
+
+// scanUTMTemplate scans a row selected with utmTemplateColumns.
+func scanUTMTemplate(row rowScanner) (*domain.UTMTemplate, error) {
+	var template domain.UTMTemplate
+	err := row.Scan(
+		&template.ID,
+		&template.Name,
+		&template.Source,
+		&template.Medium,
+		&template.Campaign,
+		&template.Term,
+		&template.Content,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+// scanAPIKey scans a row selected with apiKeyColumns.
+func scanAPIKey(row rowScanner) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&key.Admin,
+		&key.CreatedBy,
+		&key.CreatedAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/config"
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/edson-mazvila/url-shortener/internal/migration"
+	"github.com/edson-mazvila/url-shortener/internal/storage"
+)
+
+// testURLStores returns a fresh in-memory store and a fresh SQLite :memory: store with the full schema.
+func testURLStores(t *testing.T) map[string]URLStore {
+	t.Helper()
+
+	logger := slog.New(slog.DiscardHandler)
+	ctx := context.Background()
+
+	db, err := storage.NewSQLiteDB(ctx, &config.DatabaseConfig{
+		Driver:       config.DriverSQLite,
+		Path:         config.SQLiteMemoryPath,
+		MaxOpenConns: 1,
+		MaxIdleConns: 1,
+	}, logger)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	migrator, err := migration.NewMigrator(db.DB(), migration.SQLite, logger)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("migrate sqlite: %v", err)
+	}
+
+	return map[string]URLStore{
+		"memory": NewMemoryURLRepository(logger),
+		"sqlite": NewSQLiteURLRepository(db.DB(), logger),
+	}
+}
+
+// createTestURL stores a link to https://example.com under shortCode.
+func createTestURL(t *testing.T, store URLStore, shortCode string, modify func(*domain.URL)) *domain.URL {
+	t.Helper()
+
+	url := &domain.URL{
+		ShortCode:     shortCode,
+		OriginalURL:   "https://example.com/" + shortCode,
+		NormalizedURL: "https://example.com/" + shortCode,
+		CreatedAt:     time.Now(),
+	}
+	if modify != nil {
+		modify(url)
+	}
+
+	if err := store.Create(context.Background(), url, domain.Change{Action: domain.RevisionCreate, Actor: "test"}); err != nil {
+		t.Fatalf("create %s: %v", shortCode, err)
+	}
+
+	return url
+}
+
+func TestURLStoreCreateAndGet(t *testing.T) {
+	for name, store := range testURLStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			created := createTestURL(t, store, "stored", nil)
+			if created.ID == 0 {
+				t.Error("Create did not assign an ID")
+			}
+
+			tests := []struct {
+				name      string
+				shortCode string
+				wantErr   error
+			}{
+				{name: "stored code", shortCode: "stored"},
+				{name: "unknown code", shortCode: "missing", wantErr: domain.ErrURLNotFound},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					url, err := store.GetByShortCode(ctx, tt.shortCode)
+					if tt.wantErr != nil {
+						if !errors.Is(err, tt.wantErr) {
+							t.Fatalf("got err %v, want %v", err, tt.wantErr)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatalf("GetByShortCode: %v", err)
+					}
+					if url.ID != created.ID || url.OriginalURL != created.OriginalURL {
+						t.Errorf("got %d %q, want %d %q", url.ID, url.OriginalURL, created.ID, created.OriginalURL)
+					}
+				})
+			}
+
+			duplicate := &domain.URL{ShortCode: "stored", OriginalURL: "https://example.com/other", CreatedAt: time.Now()}
+			if err := store.Create(ctx, duplicate, domain.Change{Action: domain.RevisionCreate, Actor: "test"}); !errors.Is(err, domain.ErrShortCodeAlreadyExists) {
+				t.Errorf("creating a taken code: got err %v, want ErrShortCodeAlreadyExists", err)
+			}
+		})
+	}
+}
+
+func TestConsumeClickNeverExceedsMaxClicks(t *testing.T) {
+	tests := []struct {
+		maxClicks int64
+		visitors  int
+	}{
+		{maxClicks: 1, visitors: 20},
+		{maxClicks: 5, visitors: 50},
+		{maxClicks: 30, visitors: 30},
+	}
+
+	for name, store := range testURLStores(t) {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s/max=%d/visitors=%d", name, tt.maxClicks, tt.visitors), func(t *testing.T) {
+				ctx := context.Background()
+				maxClicks := tt.maxClicks
+				created := createTestURL(t, store, fmt.Sprintf("limit%d-%d", tt.maxClicks, tt.visitors), func(url *domain.URL) {
+					url.MaxClicks = &maxClicks
+				})
+
+				var (
+					wg       sync.WaitGroup
+					mu       sync.Mutex
+					consumed int64
+					failures []error
+				)
+				for range tt.visitors {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+
+						url, err := store.GetByShortCode(ctx, created.ShortCode)
+						if err == nil {
+							err = store.ConsumeClick(ctx, url)
+						}
+
+						mu.Lock()
+						defer mu.Unlock()
+						switch {
+						case err == nil:
+							consumed++
+						case !errors.Is(err, domain.ErrClickLimitReached):
+							failures = append(failures, err)
+						}
+					}()
+				}
+				wg.Wait()
+
+				for _, err := range failures {
+					t.Errorf("ConsumeClick: %v", err)
+				}
+
+				want := min(tt.maxClicks, int64(tt.visitors))
+				if consumed != want {
+					t.Errorf("consumed %d clicks, want %d", consumed, want)
+				}
+
+				stored, err := store.GetByShortCode(ctx, created.ShortCode)
+				if err != nil {
+					t.Fatalf("GetByShortCode: %v", err)
+				}
+				if stored.UsedClicks != want {
+					t.Errorf("used_clicks = %d, want %d", stored.UsedClicks, want)
+				}
+			})
+		}
+	}
+}
+
+func TestDeleteExpiredKeepsSoftDeletedAndFallbackURLs(t *testing.T) {
+	for name, store := range testURLStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			past := time.Now().Add(-time.Hour)
+
+			createTestURL(t, store, "expired", func(url *domain.URL) { url.ExpiresAt = &past })
+			createTestURL(t, store, "fallback", func(url *domain.URL) {
+				url.ExpiresAt = &past
+				url.FallbackURL = "https://example.com/gone"
+			})
+			deleted := createTestURL(t, store, "deleted", func(url *domain.URL) { url.ExpiresAt = &past })
+			deleted.DeletedAt = &past
+			if err := store.UpdateAttributes(ctx, deleted, domain.Change{Action: domain.RevisionDelete, Actor: "test"}); err != nil {
+				t.Fatalf("soft-delete: %v", err)
+			}
+			createTestURL(t, store, "current", nil)
+
+			count, err := store.DeleteExpired(ctx)
+			if err != nil {
+				t.Fatalf("DeleteExpired: %v", err)
+			}
+			if count != 1 {
+				t.Errorf("deleted %d urls, want 1", count)
+			}
+
+			if _, err := store.GetByShortCode(ctx, "expired"); !errors.Is(err, domain.ErrURLNotFound) {
+				t.Errorf("expired url: got err %v, want ErrURLNotFound", err)
+			}
+			for _, code := range []string{"fallback", "current"} {
+				if _, err := store.GetByShortCode(ctx, code); err != nil {
+					t.Errorf("%s url: %v", code, err)
+				}
+			}
+			if _, err := store.GetDeleted(ctx, "deleted"); err != nil {
+				t.Errorf("soft-deleted url: %v", err)
+			}
+		})
+	}
+}
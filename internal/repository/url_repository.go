@@ -9,9 +9,13 @@ import (
 
 	"github.com/edson-mazvila/url-shortener/internal/domain"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// pgUniqueViolation is the SQLSTATE raised for unique constraint violations.
+const pgUniqueViolation = "23505"
+
 // URLRepository handles database operations for URLs.
 type URLRepository struct {
 	pool   *pgxpool.Pool
@@ -26,31 +30,101 @@ func NewURLRepository(pool *pgxpool.Pool, logger *slog.Logger) *URLRepository {
 	}
 }
 
-// Create creates a new shortened URL in the database.
-func (r *URLRepository) Create(ctx context.Context, url *domain.URL) error {
+// NextID reserves the next value of the urls ID sequence.
+func (r *URLRepository) NextID(ctx context.Context) (int64, error) {
+	var id int64
+	err := r.pool.QueryRow(ctx, `SELECT nextval(pg_get_serial_sequence('urls', 'id'))`).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reserve url id: %w", err)
+	}
+
+	return id, nil
+}
+
+// Create creates a new shortened URL in the database and records its first revision.
+func (r *URLRepository) Create(ctx context.Context, url *domain.URL, change domain.Change) error {
 	query := `
-		INSERT INTO urls (short_code, original_url, created_at, expires_at, access_count)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id
+		INSERT INTO urls (
+			id, short_code, original_url, normalized_url, created_at, expires_at, access_count, redirect_type,
+			query_passthrough, query_precedence, path_passthrough,
+			base_url, utm_source, utm_medium, utm_campaign, utm_term, utm_content, targeting_rules,
+			variants, sticky_variants, password_hash, max_clicks, activates_at, always_preview, fallback_url
+		)
+		VALUES (
+			COALESCE($1, nextval(pg_get_serial_sequence('urls', 'id'))), $2, $3, NULLIF($4, ''), $5, $6, $7, $8,
+			$9, $10, $11,
+			$12, $13, $14, $15, $16, $17, $18,
+			$19, $20, $21, $22, $23, $24, $25
+		)
+		RETURNING id, version
 	`
 
-	err := r.pool.QueryRow(
+	var id *int64
+	if url.ID != 0 {
+		id = &url.ID
+	}
+	utm := urlUTM(url)
+	rules, err := encodeJSONList(url.TargetingRules, "targeting rules")
+	if err != nil {
+		return err
+	}
+	variants, err := encodeJSONList(url.Variants, "variants")
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(
 		ctx,
 		query,
+		id,
 		url.ShortCode,
 		url.OriginalURL,
+		url.NormalizedURL,
 		url.CreatedAt,
 		url.ExpiresAt,
 		url.AccessCount,
-	).Scan(&url.ID)
+		url.RedirectType,
+		url.QueryPassthrough,
+		url.QueryPrecedence,
+		url.PathPassthrough,
+		url.BaseURL,
+		utm.Source,
+		utm.Medium,
+		utm.Campaign,
+		utm.Term,
+		utm.Content,
+		rules,
+		variants,
+		url.StickyVariants,
+		url.PasswordHash,
+		url.MaxClicks,
+		url.ActivatesAt,
+		url.AlwaysPreview,
+		url.FallbackURL,
+	).Scan(&url.ID, &url.Version)
 
 	if err != nil {
-		if err.Error() == "ERROR: duplicate key value violates unique constraint \"urls_short_code_key\" (SQLSTATE 23505)" {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
 			return domain.ErrShortCodeAlreadyExists
 		}
 		return fmt.Errorf("failed to create url: %w", err)
 	}
 
+	if err := r.insertRevision(ctx, tx, url, change, url.CreatedAt); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit url: %w", err)
+	}
+
 	r.logger.Debug("url created",
 		slog.Int64("id", url.ID),
 		slog.String("short_code", url.ShortCode),
@@ -62,21 +136,12 @@ func (r *URLRepository) Create(ctx context.Context, url *domain.URL) error {
 // GetByShortCode retrieves a URL by its short code.
 func (r *URLRepository) GetByShortCode(ctx context.Context, shortCode string) (*domain.URL, error) {
 	query := `
-		SELECT id, short_code, original_url, created_at, expires_at, access_count, last_accessed
+		SELECT ` + urlColumns + `
 		FROM urls
-		WHERE short_code = $1
+		WHERE short_code = $1 AND deleted_at IS NULL
 	`
 
-	var url domain.URL
-	err := r.pool.QueryRow(ctx, query, shortCode).Scan(
-		&url.ID,
-		&url.ShortCode,
-		&url.OriginalURL,
-		&url.CreatedAt,
-		&url.ExpiresAt,
-		&url.AccessCount,
-		&url.LastAccessed,
-	)
+	url, err := scanURL(r.pool.QueryRow(ctx, query, shortCode))
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -85,27 +150,38 @@ func (r *URLRepository) GetByShortCode(ctx context.Context, shortCode string) (*
 		return nil, fmt.Errorf("failed to get url by short code: %w", err)
 	}
 
-	return &url, nil
+	return url, nil
 }
 
-// GetByID retrieves a URL by its ID.
+// GetDeleted retrieves a soft-deleted URL by its short code.
+func (r *URLRepository) GetDeleted(ctx context.Context, shortCode string) (*domain.URL, error) {
+	query := `
+		SELECT ` + urlColumns + `
+		FROM urls
+		WHERE short_code = $1 AND deleted_at IS NOT NULL
+	`
+
+	url, err := scanURL(r.pool.QueryRow(ctx, query, shortCode))
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrURLNotFound
+		}
+		return nil, fmt.Errorf("failed to get deleted url: %w", err)
+	}
+
+	return url, nil
+}
+
+// GetByID retrieves a URL by its ID, including soft-deleted URLs.
 func (r *URLRepository) GetByID(ctx context.Context, id int64) (*domain.URL, error) {
 	query := `
-		SELECT id, short_code, original_url, created_at, expires_at, access_count, last_accessed
+		SELECT ` + urlColumns + `
 		FROM urls
 		WHERE id = $1
 	`
 
-	var url domain.URL
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&url.ID,
-		&url.ShortCode,
-		&url.OriginalURL,
-		&url.CreatedAt,
-		&url.ExpiresAt,
-		&url.AccessCount,
-		&url.LastAccessed,
-	)
+	url, err := scanURL(r.pool.QueryRow(ctx, query, id))
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -114,7 +190,31 @@ func (r *URLRepository) GetByID(ctx context.Context, id int64) (*domain.URL, err
 		return nil, fmt.Errorf("failed to get url by id: %w", err)
 	}
 
-	return &url, nil
+	return url, nil
+}
+
+// FindByNormalizedURL returns the most recently created unexpired URL with the given normalized destination.
+func (r *URLRepository) FindByNormalizedURL(ctx context.Context, normalizedURL string) (*domain.URL, error) {
+	query := `
+		SELECT ` + urlColumns + `
+		FROM urls
+		WHERE normalized_url = $1 AND (expires_at IS NULL OR expires_at > $2) AND deleted_at IS NULL
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`
+
+	url, err := scanURL(r.pool.QueryRow(ctx, query, normalizedURL, time.Now()))
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrURLNotFound
+		}
+		return nil, fmt.Errorf("failed to find url by normalized url: %w", err)
+	}
+
+	url.NormalizedURL = normalizedURL
+
+	return url, nil
 }
 
 // Update updates an existing URL.
@@ -142,29 +242,211 @@ func (r *URLRepository) Update(ctx context.Context, url *domain.URL) error {
 	return nil
 }
 
-// Delete deletes a URL by its short code.
-func (r *URLRepository) Delete(ctx context.Context, shortCode string) error {
-	query := `DELETE FROM urls WHERE short_code = $1`
+// UpdateAttributes overwrites the editable attributes of a URL if its version is unchanged
+// and records the change in the URL's history.
+func (r *URLRepository) UpdateAttributes(ctx context.Context, url *domain.URL, change domain.Change) error {
+	query := `
+		UPDATE urls
+		SET original_url = $1, normalized_url = NULLIF($2, ''), expires_at = $3, redirect_type = $4,
+		    query_passthrough = $5, query_precedence = $6, path_passthrough = $7,
+		    base_url = $8, utm_source = $9, utm_medium = $10, utm_campaign = $11, utm_term = $12, utm_content = $13,
+		    targeting_rules = $14, variants = $15, sticky_variants = $16, password_hash = $17, max_clicks = $18,
+		    activates_at = $19, disabled_at = $20, deleted_at = $21, always_preview = $22, fallback_url = $23,
+		    version = version + 1
+		WHERE id = $24 AND version = $25
+		RETURNING version
+	`
+	utm := urlUTM(url)
+	rules, err := encodeJSONList(url.TargetingRules, "targeting rules")
+	if err != nil {
+		return err
+	}
+	variants, err := encodeJSONList(url.Variants, "variants")
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, query,
+		url.OriginalURL,
+		url.NormalizedURL,
+		url.ExpiresAt,
+		url.RedirectType,
+		url.QueryPassthrough,
+		url.QueryPrecedence,
+		url.PathPassthrough,
+		url.BaseURL,
+		utm.Source,
+		utm.Medium,
+		utm.Campaign,
+		utm.Term,
+		utm.Content,
+		rules,
+		variants,
+		url.StickyVariants,
+		url.PasswordHash,
+		url.MaxClicks,
+		url.ActivatesAt,
+		url.DisabledAt,
+		url.DeletedAt,
+		url.AlwaysPreview,
+		url.FallbackURL,
+		url.ID,
+		url.Version,
+	).Scan(&url.Version)
 
-	result, err := r.pool.Exec(ctx, query, shortCode)
 	if err != nil {
-		return fmt.Errorf("failed to delete url: %w", err)
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to update url attributes: %w", err)
+		}
+		if _, err := r.GetByID(ctx, url.ID); err != nil {
+			return err
+		}
+		return domain.ErrVersionMismatch
 	}
 
-	if result.RowsAffected() == 0 {
-		return domain.ErrURLNotFound
+	if err := r.insertRevision(ctx, tx, url, change, time.Now()); err != nil {
+		return err
 	}
 
-	r.logger.Debug("url deleted", slog.String("short_code", shortCode))
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit url attributes: %w", err)
+	}
+
+	r.logger.Debug("url attributes updated",
+		slog.Int64("id", url.ID),
+		slog.Int64("version", url.Version),
+	)
 
 	return nil
 }
 
-// DeleteExpired deletes all expired URLs.
+// ListRevisions returns the history of a URL, newest first.
+func (r *URLRepository) ListRevisions(ctx context.Context, urlID int64) ([]*domain.URLRevision, error) {
+	query := `
+		SELECT ` + revisionColumns + `
+		FROM url_history
+		WHERE url_id = $1
+		ORDER BY version DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, urlID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list url revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []*domain.URLRevision
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan url revision row: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating url revision rows: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// GetRevision retrieves one version of a URL's history.
+func (r *URLRepository) GetRevision(ctx context.Context, urlID, version int64) (*domain.URLRevision, error) {
+	query := `
+		SELECT ` + revisionColumns + `
+		FROM url_history
+		WHERE url_id = $1 AND version = $2
+	`
+
+	revision, err := scanRevision(r.pool.QueryRow(ctx, query, urlID, version))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrRevisionNotFound
+		}
+		return nil, fmt.Errorf("failed to get url revision: %w", err)
+	}
+
+	return revision, nil
+}
+
+func (r *URLRepository) insertRevision(ctx context.Context, tx pgx.Tx, url *domain.URL, change domain.Change, changedAt time.Time) error {
+	query := `
+		INSERT INTO url_history (url_id, version, action, original_url, expires_at, restored_version, changed_by, changed_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0), $7, $8)
+	`
+
+	_, err := tx.Exec(ctx, query,
+		url.ID,
+		url.Version,
+		change.Action,
+		url.OriginalURL,
+		url.ExpiresAt,
+		change.RestoredVersion,
+		change.Actor,
+		changedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record url revision: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeDeleted permanently deletes URLs soft-deleted before deletedBefore.
+func (r *URLRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	query := `DELETE FROM urls WHERE deleted_at IS NOT NULL AND deleted_at < $1`
+
+	result, err := r.pool.Exec(ctx, query, deletedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted urls: %w", err)
+	}
+
+	count := result.RowsAffected()
+	if count > 0 {
+		r.logger.Info("deleted urls purged", slog.Int64("count", count))
+	}
+
+	return count, nil
+}
+
+// ConsumeClick atomically counts a redirect against the click limit of a URL.
+func (r *URLRepository) ConsumeClick(ctx context.Context, url *domain.URL) error {
+	query := `
+		UPDATE urls
+		SET used_clicks = used_clicks + 1
+		WHERE id = $1 AND (max_clicks IS NULL OR used_clicks < max_clicks)
+		RETURNING used_clicks
+	`
+
+	err := r.pool.QueryRow(ctx, query, url.ID).Scan(&url.UsedClicks)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to consume click: %w", err)
+		}
+		if _, err := r.GetByID(ctx, url.ID); err != nil {
+			return err
+		}
+		return domain.ErrClickLimitReached
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes all expired and exhausted URLs without a fallback URL. Soft-deleted URLs are
+// left to PurgeDeleted.
 func (r *URLRepository) DeleteExpired(ctx context.Context) (int64, error) {
 	query := `
 		DELETE FROM urls
-		WHERE expires_at IS NOT NULL AND expires_at < $1
+		WHERE fallback_url = '' AND deleted_at IS NULL
+		  AND ((expires_at IS NOT NULL AND expires_at < $1)
+		   OR (max_clicks IS NOT NULL AND used_clicks >= max_clicks))
 	`
 
 	result, err := r.pool.Exec(ctx, query, time.Now())
@@ -183,8 +465,9 @@ func (r *URLRepository) DeleteExpired(ctx context.Context) (int64, error) {
 // List retrieves a paginated list of URLs.
 func (r *URLRepository) List(ctx context.Context, limit, offset int) ([]*domain.URL, error) {
 	query := `
-		SELECT id, short_code, original_url, created_at, expires_at, access_count, last_accessed
+		SELECT ` + urlColumns + `
 		FROM urls
+		WHERE deleted_at IS NULL
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
 	`
@@ -197,20 +480,11 @@ func (r *URLRepository) List(ctx context.Context, limit, offset int) ([]*domain.
 
 	var urls []*domain.URL
 	for rows.Next() {
-		var url domain.URL
-		err := rows.Scan(
-			&url.ID,
-			&url.ShortCode,
-			&url.OriginalURL,
-			&url.CreatedAt,
-			&url.ExpiresAt,
-			&url.AccessCount,
-			&url.LastAccessed,
-		)
+		url, err := scanURL(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan url row: %w", err)
 		}
-		urls = append(urls, &url)
+		urls = append(urls, url)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -220,9 +494,44 @@ func (r *URLRepository) List(ctx context.Context, limit, offset int) ([]*domain.
 	return urls, nil
 }
 
-// Count returns the total number of URLs in the database.
+// IncrementAccessCounts atomically adds buffered access counts to their URLs in one batch.
+func (r *URLRepository) IncrementAccessCounts(ctx context.Context, deltas map[string]AccessDelta) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	query := `
+		UPDATE urls
+		SET access_count = access_count + $1,
+		    last_accessed = GREATEST(COALESCE(last_accessed, $2), $2)
+		WHERE short_code = $3
+	`
+
+	batch := &pgx.Batch{}
+	for shortCode, delta := range deltas {
+		batch.Queue(query, delta.Count, delta.LastAccessed, shortCode)
+	}
+
+	results := r.pool.SendBatch(ctx, batch)
+	for range deltas {
+		if _, err := results.Exec(); err != nil {
+			results.Close()
+			return fmt.Errorf("failed to increment access counts: %w", err)
+		}
+	}
+
+	if err := results.Close(); err != nil {
+		return fmt.Errorf("failed to increment access counts: %w", err)
+	}
+
+	r.logger.Debug("access counts flushed", slog.Int("urls", len(deltas)))
+
+	return nil
+}
+
+// Count returns the number of URLs that have not been deleted.
 func (r *URLRepository) Count(ctx context.Context) (int64, error) {
-	query := `SELECT COUNT(*) FROM urls`
+	query := `SELECT COUNT(*) FROM urls WHERE deleted_at IS NULL`
 
 	var count int64
 	err := r.pool.QueryRow(ctx, query).Scan(&count)
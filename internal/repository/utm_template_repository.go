@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UTMTemplateRepository handles database operations for UTM templates.
+type UTMTemplateRepository struct {
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+// NewUTMTemplateRepository creates a new UTM template repository.
+func NewUTMTemplateRepository(pool *pgxpool.Pool, logger *slog.Logger) *UTMTemplateRepository {
+	return &UTMTemplateRepository{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// CreateUTMTemplate creates a new UTM template.
+func (r *UTMTemplateRepository) CreateUTMTemplate(ctx context.Context, template *domain.UTMTemplate) error {
+	query := `
+		INSERT INTO utm_templates (name, utm_source, utm_medium, utm_campaign, utm_term, utm_content, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+
+	err := r.pool.QueryRow(ctx, query,
+		template.Name,
+		template.Source,
+		template.Medium,
+		template.Campaign,
+		template.Term,
+		template.Content,
+		template.CreatedAt,
+		template.UpdatedAt,
+	).Scan(&template.ID)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return domain.ErrUTMTemplateExists
+		}
+		return fmt.Errorf("failed to create utm template: %w", err)
+	}
+
+	r.logger.Debug("utm template created", slog.String("name", template.Name))
+
+	return nil
+}
+
+// GetUTMTemplate retrieves a UTM template by name.
+func (r *UTMTemplateRepository) GetUTMTemplate(ctx context.Context, name string) (*domain.UTMTemplate, error) {
+	query := `SELECT ` + utmTemplateColumns + ` FROM utm_templates WHERE name = $1`
+
+	template, err := scanUTMTemplate(r.pool.QueryRow(ctx, query, name))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrUTMTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to get utm template: %w", err)
+	}
+
+	return template, nil
+}
+
+// ListUTMTemplates retrieves all UTM templates ordered by name.
+func (r *UTMTemplateRepository) ListUTMTemplates(ctx context.Context) ([]*domain.UTMTemplate, error) {
+	query := `SELECT ` + utmTemplateColumns + ` FROM utm_templates ORDER BY name`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list utm templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*domain.UTMTemplate
+	for rows.Next() {
+		template, err := scanUTMTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan utm template: %w", err)
+		}
+		templates = append(templates, template)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating utm templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// UpdateUTMTemplate overwrites the parameters of an existing UTM template.
+func (r *UTMTemplateRepository) UpdateUTMTemplate(ctx context.Context, template *domain.UTMTemplate) error {
+	query := `
+		UPDATE utm_templates
+		SET utm_source = $1, utm_medium = $2, utm_campaign = $3, utm_term = $4, utm_content = $5, updated_at = $6
+		WHERE name = $7
+		RETURNING id, created_at
+	`
+
+	err := r.pool.QueryRow(ctx, query,
+		template.Source,
+		template.Medium,
+		template.Campaign,
+		template.Term,
+		template.Content,
+		template.UpdatedAt,
+		template.Name,
+	).Scan(&template.ID, &template.CreatedAt)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrUTMTemplateNotFound
+		}
+		return fmt.Errorf("failed to update utm template: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteUTMTemplate deletes a UTM template by name.
+func (r *UTMTemplateRepository) DeleteUTMTemplate(ctx context.Context, name string) error {
+	query := `DELETE FROM utm_templates WHERE name = $1`
+
+	result, err := r.pool.Exec(ctx, query, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete utm template: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrUTMTemplateNotFound
+	}
+
+	r.logger.Debug("utm template deleted", slog.String("name", name))
+
+	return nil
+}
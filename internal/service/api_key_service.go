@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/config"
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/edson-mazvila/url-shortener/internal/repository"
+)
+
+const (
+	// BootstrapKeyName names the admin key configured with AUTH_BOOTSTRAP_KEY.
+	BootstrapKeyName = "bootstrap"
+
+	// apiKeyPrefix starts every issued key, so leaked keys are easy to recognise.
+	apiKeyPrefix = "usk_"
+	// apiKeySecretBytes is the number of random bytes in an issued key.
+	apiKeySecretBytes = 24
+	// apiKeyDisplayLength is how many leading characters of a key are stored in the clear.
+	apiKeyDisplayLength = 12
+	// maxAPIKeyNameLength matches the api_keys.name column.
+	maxAPIKeyNameLength = 64
+	// apiKeyTouchInterval limits how often a key's last use is written, so busy clients do not turn
+	// every request into a database write.
+	apiKeyTouchInterval = time.Minute
+)
+
+// APIKeyService issues, revokes and checks the keys that grant access to the management API.
+type APIKeyService struct {
+	store         repository.APIKeyStore
+	bootstrapHash string
+	logger        *slog.Logger
+}
+
+// NewAPIKeyService creates a new API key service.
+func NewAPIKeyService(store repository.APIKeyStore, cfg *config.AuthConfig, logger *slog.Logger) *APIKeyService {
+	s := &APIKeyService{
+		store:  store,
+		logger: logger,
+	}
+
+	if cfg.BootstrapKey != "" {
+		s.bootstrapHash = hashAPIKey(cfg.BootstrapKey)
+	}
+
+	return s
+}
+
+// CreateKey issues a new API key and returns it together with its secret, which is not stored and
+// cannot be retrieved again.
+func (s *APIKeyService) CreateKey(ctx context.Context, name string, admin bool, actor string) (*domain.APIKey, string, error) {
+	name, err := validateAPIKeyName(name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	random := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	secret := apiKeyPrefix + hex.EncodeToString(random)
+
+	key := &domain.APIKey{
+		Name:      name,
+		Prefix:    secret[:apiKeyDisplayLength],
+		KeyHash:   hashAPIKey(secret),
+		Admin:     admin,
+		CreatedBy: actor,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.store.CreateAPIKey(ctx, key); err != nil {
+		return nil, "", fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	s.logger.Info("api key created",
+		slog.Int64("id", key.ID),
+		slog.String("prefix", key.Prefix),
+		slog.Bool("admin", admin),
+		slog.String("actor", actor),
+	)
+
+	return key, secret, nil
+}
+
+// GetKey retrieves an API key by ID.
+func (s *APIKeyService) GetKey(ctx context.Context, id int64) (*domain.APIKey, error) {
+	return s.store.GetAPIKey(ctx, id)
+}
+
+// ListKeys retrieves all API keys, revoked ones included.
+func (s *APIKeyService) ListKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	keys, err := s.store.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RenameKey changes the name of an API key.
+func (s *APIKeyService) RenameKey(ctx context.Context, id int64, name string) (*domain.APIKey, error) {
+	name, err := validateAPIKeyName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.RenameAPIKey(ctx, id, name); err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to rename api key: %w", err)
+	}
+
+	return s.store.GetAPIKey(ctx, id)
+}
+
+// RevokeKey revokes an API key, which stops authenticating requests at once. Revoked keys are kept so
+// their history stays readable.
+func (s *APIKeyService) RevokeKey(ctx context.Context, id int64, actor string) error {
+	if err := s.store.RevokeAPIKey(ctx, id, time.Now()); err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	s.logger.Info("api key revoked", slog.Int64("id", id), slog.String("actor", actor))
+
+	return nil
+}
+
+// Authenticate returns the API key secret belongs to, or domain.ErrInvalidAPIKey if it is unknown or
+// revoked. The bootstrap key is returned as an admin key named BootstrapKeyName with ID 0.
+func (s *APIKeyService) Authenticate(ctx context.Context, secret string) (*domain.APIKey, error) {
+	hash := hashAPIKey(secret)
+
+	if s.bootstrapHash != "" && subtle.ConstantTimeCompare([]byte(hash), []byte(s.bootstrapHash)) == 1 {
+		return &domain.APIKey{Name: BootstrapKeyName, Admin: true}, nil
+	}
+
+	key, err := s.store.GetAPIKeyByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return nil, domain.ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("failed to look up api key: %w", err)
+	}
+
+	if key.IsRevoked() {
+		return nil, domain.ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.store.TouchAPIKey(ctx, key.ID, now); err != nil {
+			s.logger.Warn("failed to record api key use",
+				slog.Int64("id", key.ID),
+				slog.String("error", err.Error()),
+			)
+		} else {
+			key.LastUsedAt = &now
+		}
+	}
+
+	return key, nil
+}
+
+// hashAPIKey returns the hex-encoded SHA-256 hash a key is stored and looked up by. Keys are long and
+// random, so unlike link passwords they need no slow, salted hash.
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func validateAPIKeyName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxAPIKeyNameLength {
+		return "", fmt.Errorf("name must be 1-%d characters: %w", maxAPIKeyNameLength, domain.ErrInvalidAPIKeyName)
+	}
+	return name, nil
+}
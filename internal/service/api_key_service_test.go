@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/edson-mazvila/url-shortener/internal/config"
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+)
+
+const testBootstrapKey = "bootstrap-secret-0123456789abcdef"
+
+func TestAPIKeyServiceAuthenticate(t *testing.T) {
+	for _, backend := range testBackends(t) {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc := NewAPIKeyService(backend.apiKeys, &config.AuthConfig{BootstrapKey: testBootstrapKey}, slog.New(slog.DiscardHandler))
+
+			issued, secret, err := svc.CreateKey(ctx, "  deploy bot  ", false, "bootstrap")
+			if err != nil {
+				t.Fatalf("CreateKey: %v", err)
+			}
+			if issued.Name != "deploy bot" || issued.CreatedBy != "bootstrap" {
+				t.Errorf("created key %+v, want the trimmed name and the actor", issued)
+			}
+			if !strings.HasPrefix(secret, apiKeyPrefix) || !strings.HasPrefix(secret, issued.Prefix) {
+				t.Errorf("secret %q does not start with %q and the key prefix %q", secret, apiKeyPrefix, issued.Prefix)
+			}
+
+			revoked, revokedSecret, err := svc.CreateKey(ctx, "old", true, "bootstrap")
+			if err != nil {
+				t.Fatalf("CreateKey: %v", err)
+			}
+			if err := svc.RevokeKey(ctx, revoked.ID, "bootstrap"); err != nil {
+				t.Fatalf("RevokeKey: %v", err)
+			}
+
+			tests := []struct {
+				name      string
+				secret    string
+				wantErr   error
+				wantName  string
+				wantAdmin bool
+			}{
+				{name: "issued key", secret: secret, wantName: "deploy bot"},
+				{name: "bootstrap key", secret: testBootstrapKey, wantName: BootstrapKeyName, wantAdmin: true},
+				{name: "revoked key", secret: revokedSecret, wantErr: domain.ErrInvalidAPIKey},
+				{name: "unknown key", secret: apiKeyPrefix + strings.Repeat("0", 2*apiKeySecretBytes), wantErr: domain.ErrInvalidAPIKey},
+				{name: "key prefix only", secret: issued.Prefix, wantErr: domain.ErrInvalidAPIKey},
+				{name: "stored hash", secret: issued.KeyHash, wantErr: domain.ErrInvalidAPIKey},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					key, err := svc.Authenticate(ctx, tt.secret)
+					if tt.wantErr != nil {
+						if !errors.Is(err, tt.wantErr) {
+							t.Fatalf("got err %v, want %v", err, tt.wantErr)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatalf("Authenticate: %v", err)
+					}
+					if key.Name != tt.wantName || key.Admin != tt.wantAdmin {
+						t.Errorf("authenticated as %q (admin %v), want %q (admin %v)", key.Name, key.Admin, tt.wantName, tt.wantAdmin)
+					}
+				})
+			}
+
+			stored, err := svc.GetKey(ctx, issued.ID)
+			if err != nil {
+				t.Fatalf("GetKey: %v", err)
+			}
+			if stored.LastUsedAt == nil {
+				t.Error("authenticating did not record the key's last use")
+			}
+		})
+	}
+}
+
+func TestAPIKeyServiceRevokeKey(t *testing.T) {
+	for _, backend := range testBackends(t) {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc := NewAPIKeyService(backend.apiKeys, &config.AuthConfig{}, slog.New(slog.DiscardHandler))
+
+			key, secret, err := svc.CreateKey(ctx, "ci", false, "bootstrap")
+			if err != nil {
+				t.Fatalf("CreateKey: %v", err)
+			}
+
+			if err := svc.RevokeKey(ctx, key.ID, "bootstrap"); err != nil {
+				t.Fatalf("RevokeKey: %v", err)
+			}
+			first, err := svc.GetKey(ctx, key.ID)
+			if err != nil {
+				t.Fatalf("GetKey: %v", err)
+			}
+			if !first.IsRevoked() {
+				t.Fatal("key is not revoked")
+			}
+
+			if err := svc.RevokeKey(ctx, key.ID, "bootstrap"); err != nil {
+				t.Fatalf("revoking again: %v", err)
+			}
+			second, err := svc.GetKey(ctx, key.ID)
+			if err != nil {
+				t.Fatalf("GetKey: %v", err)
+			}
+			if !second.RevokedAt.Equal(*first.RevokedAt) {
+				t.Errorf("revoking again moved the revocation time from %v to %v", first.RevokedAt, second.RevokedAt)
+			}
+
+			if _, err := svc.Authenticate(ctx, secret); !errors.Is(err, domain.ErrInvalidAPIKey) {
+				t.Errorf("revoked key authenticated: %v", err)
+			}
+			if err := svc.RevokeKey(ctx, key.ID+100, "bootstrap"); !errors.Is(err, domain.ErrAPIKeyNotFound) {
+				t.Errorf("revoking an unknown key: got err %v, want ErrAPIKeyNotFound", err)
+			}
+		})
+	}
+}
+
+func TestAPIKeyServiceValidatesNames(t *testing.T) {
+	svc := NewAPIKeyService(testBackends(t)[0].apiKeys, &config.AuthConfig{}, slog.New(slog.DiscardHandler))
+
+	for _, name := range []string{"", "   ", strings.Repeat("n", maxAPIKeyNameLength+1)} {
+		if _, _, err := svc.CreateKey(context.Background(), name, false, "bootstrap"); !errors.Is(err, domain.ErrInvalidAPIKeyName) {
+			t.Errorf("CreateKey(%q): got err %v, want ErrInvalidAPIKeyName", name, err)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/config"
+	"github.com/edson-mazvila/url-shortener/internal/repository"
+)
+
+// ClickAggregator buffers access counts in memory and persists them in periodic batches.
+type ClickAggregator struct {
+	store  repository.URLStore
+	config *config.AnalyticsConfig
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	pending map[string]repository.AccessDelta
+
+	flushMu sync.Mutex
+	full    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewClickAggregator creates a new click aggregator.
+func NewClickAggregator(store repository.URLStore, cfg *config.AnalyticsConfig, logger *slog.Logger) *ClickAggregator {
+	return &ClickAggregator{
+		store:   store,
+		config:  cfg,
+		logger:  logger,
+		pending: make(map[string]repository.AccessDelta),
+		full:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Record buffers a single access to the given short code.
+func (a *ClickAggregator) Record(shortCode string, at time.Time) {
+	a.mu.Lock()
+	delta := a.pending[shortCode]
+	delta.Count++
+	if at.After(delta.LastAccessed) {
+		delta.LastAccessed = at
+	}
+	a.pending[shortCode] = delta
+	size := len(a.pending)
+	a.mu.Unlock()
+
+	if size >= a.config.MaxPending {
+		select {
+		case a.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Start launches the background flush loop.
+func (a *ClickAggregator) Start() {
+	go a.run()
+}
+
+// Close stops the flush loop and persists any remaining buffered clicks.
+func (a *ClickAggregator) Close(ctx context.Context) error {
+	close(a.stop)
+
+	select {
+	case <-a.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return a.Flush(ctx)
+}
+
+// Flush writes all buffered clicks to the store. Clicks that fail to persist are kept for the next flush.
+func (a *ClickAggregator) Flush(ctx context.Context) error {
+	a.flushMu.Lock()
+	defer a.flushMu.Unlock()
+
+	a.mu.Lock()
+	batch := a.pending
+	a.pending = make(map[string]repository.AccessDelta, len(batch))
+	a.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	if err := a.store.IncrementAccessCounts(ctx, batch); err != nil {
+		a.requeue(batch)
+		return fmt.Errorf("failed to flush clicks: %w", err)
+	}
+
+	return nil
+}
+
+func (a *ClickAggregator) requeue(batch map[string]repository.AccessDelta) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for shortCode, delta := range batch {
+		current := a.pending[shortCode]
+		current.Count += delta.Count
+		if delta.LastAccessed.After(current.LastAccessed) {
+			current.LastAccessed = delta.LastAccessed
+		}
+		a.pending[shortCode] = current
+	}
+}
+
+func (a *ClickAggregator) run() {
+	defer close(a.done)
+
+	ticker := time.NewTicker(a.config.FlushInterval)
+	defer ticker.Stop()
+
+	a.logger.Info("click aggregator started",
+		slog.Duration("flush_interval", a.config.FlushInterval),
+	)
+
+	for {
+		select {
+		case <-a.stop:
+			a.logger.Info("click aggregator stopped")
+			return
+		case <-ticker.C:
+		case <-a.full:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := a.Flush(ctx); err != nil {
+			a.logger.Error("click flush failed", slog.String("error", err.Error()))
+		}
+		cancel()
+	}
+}
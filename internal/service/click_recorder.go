@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/config"
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/edson-mazvila/url-shortener/internal/repository"
+)
+
+// ClickRecorder writes click events through a bounded queue so redirects never wait on analytics.
+type ClickRecorder struct {
+	store  repository.ClickStore
+	config *config.AnalyticsConfig
+	logger *slog.Logger
+
+	queue   chan *domain.Click
+	dropped atomic.Int64
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewClickRecorder creates a new click recorder.
+func NewClickRecorder(store repository.ClickStore, cfg *config.AnalyticsConfig, logger *slog.Logger) *ClickRecorder {
+	return &ClickRecorder{
+		store:  store,
+		config: cfg,
+		logger: logger,
+		queue:  make(chan *domain.Click, cfg.QueueSize),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Enqueue queues a click event for persistence. The event is dropped if the queue is full.
+func (r *ClickRecorder) Enqueue(click *domain.Click) {
+	select {
+	case r.queue <- click:
+	default:
+		if dropped := r.dropped.Add(1); dropped%1000 == 1 {
+			r.logger.Warn("click queue full, dropping events",
+				slog.Int64("dropped_total", dropped),
+			)
+		}
+	}
+}
+
+// Dropped returns the number of click events discarded because the queue was full.
+func (r *ClickRecorder) Dropped() int64 {
+	return r.dropped.Load()
+}
+
+// Start launches the background writer.
+func (r *ClickRecorder) Start() {
+	go r.run()
+}
+
+// Close stops the writer after persisting every queued event.
+func (r *ClickRecorder) Close(ctx context.Context) error {
+	close(r.stop)
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *ClickRecorder) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*domain.Click, 0, r.config.BatchSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := r.store.CreateClicks(ctx, batch); err != nil {
+			r.logger.Error("failed to record clicks",
+				slog.String("error", err.Error()),
+				slog.Int("count", len(batch)),
+			)
+		}
+		batch = batch[:0]
+	}
+
+	r.logger.Info("click recorder started",
+		slog.Int("queue_size", r.config.QueueSize),
+		slog.Int("batch_size", r.config.BatchSize),
+	)
+
+	for {
+		select {
+		case click := <-r.queue:
+			batch = append(batch, click)
+			if len(batch) >= r.config.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-r.stop:
+			for {
+				select {
+				case click := <-r.queue:
+					batch = append(batch, click)
+					if len(batch) >= r.config.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					r.logger.Info("click recorder stopped")
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"sync/atomic"
+
+	"github.com/edson-mazvila/url-shortener/internal/config"
+)
+
+const base62Alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+const (
+	// maxCodeLength is the longest code the urls.short_code column can hold.
+	maxCodeLength = 20
+	// attemptsPerLength is how many colliding candidates are tried before a longer one.
+	attemptsPerLength = 3
+	// minWordCodeDigits is the number of digits word codes start with.
+	minWordCodeDigits = 2
+	// maxWordCodeDigits fills maxCodeLength with two six-letter words, two hyphens and the number.
+	maxWordCodeDigits = 6
+)
+
+// CodeGenerator produces candidate short codes for new URLs.
+type CodeGenerator interface {
+	// Generate returns a candidate short code. id is the reserved row ID when UsesID is true, otherwise 0.
+	// attempt counts earlier collisions for the same URL so later candidates can be made longer.
+	Generate(id int64, attempt int) (string, error)
+	// UsesID reports whether the generator derives codes from a reserved row ID.
+	UsesID() bool
+	// Length returns the length of codes currently generated, or 0 if it varies.
+	Length() int
+	// KeyspaceSize returns the number of distinct codes available at the current length.
+	KeyspaceSize() float64
+}
+
+// LengthGrower is implemented by generators whose base code length can grow under collision pressure.
+type LengthGrower interface {
+	// Grow increases the base code length and reports whether it changed.
+	Grow() bool
+}
+
+// NewCodeGenerator creates the code generator selected by cfg.CodeStrategy.
+func NewCodeGenerator(cfg *config.URLConfig) (CodeGenerator, error) {
+	switch cfg.CodeStrategy {
+	case config.CodeStrategyRandom, "":
+		return NewRandomCodeGenerator(cfg.ShortCodeLength), nil
+	case config.CodeStrategySequential:
+		return &SequentialCodeGenerator{length: cfg.ShortCodeLength}, nil
+	case config.CodeStrategyHashids:
+		return NewHashidsCodeGenerator(cfg.CodeAlphabet, cfg.CodeSalt, cfg.ShortCodeLength)
+	case config.CodeStrategyWords:
+		return NewWordCodeGenerator(), nil
+	default:
+		return nil, fmt.Errorf("unknown code strategy: %s", cfg.CodeStrategy)
+	}
+}
+
+// RandomCodeGenerator produces random base62 codes. Its length starts at the configured
+// value and grows when collisions become frequent.
+type RandomCodeGenerator struct {
+	length atomic.Int32
+}
+
+// NewRandomCodeGenerator creates a random code generator with the given initial length.
+func NewRandomCodeGenerator(length int) *RandomCodeGenerator {
+	g := &RandomCodeGenerator{}
+	g.length.Store(int32(length))
+	return g
+}
+
+// Generate returns a random base62 code, one character longer for every few failed attempts.
+func (g *RandomCodeGenerator) Generate(_ int64, attempt int) (string, error) {
+	length := min(g.Length()+attempt/attemptsPerLength, maxCodeLength)
+	return generateRandomCode(length)
+}
+
+// UsesID reports false; random codes are independent of row IDs.
+func (g *RandomCodeGenerator) UsesID() bool {
+	return false
+}
+
+// Length returns the current base code length.
+func (g *RandomCodeGenerator) Length() int {
+	return int(g.length.Load())
+}
+
+// KeyspaceSize returns 62^length.
+func (g *RandomCodeGenerator) KeyspaceSize() float64 {
+	return math.Pow(float64(len(base62Alphabet)), float64(g.Length()))
+}
+
+// Grow increases the base code length by one, up to the column limit.
+func (g *RandomCodeGenerator) Grow() bool {
+	for {
+		current := g.length.Load()
+		if current >= maxCodeLength {
+			return false
+		}
+		if g.length.CompareAndSwap(current, current+1) {
+			return true
+		}
+	}
+}
+
+// SequentialCodeGenerator encodes the row ID in base62, left-padded to the configured length.
+type SequentialCodeGenerator struct {
+	length int
+}
+
+// Generate returns the base62 encoding of id.
+func (g *SequentialCodeGenerator) Generate(id int64, _ int) (string, error) {
+	if id <= 0 {
+		return "", fmt.Errorf("sequential codes require a positive id, got %d", id)
+	}
+
+	code := encodeBase(uint64(id), base62Alphabet)
+	if len(code) < g.length {
+		code = strings.Repeat(base62Alphabet[:1], g.length-len(code)) + code
+	}
+
+	return code, nil
+}
+
+// UsesID reports true.
+func (g *SequentialCodeGenerator) UsesID() bool {
+	return true
+}
+
+// Length returns the padded code length.
+func (g *SequentialCodeGenerator) Length() int {
+	return g.length
+}
+
+// KeyspaceSize returns 62^length; later IDs produce longer codes.
+func (g *SequentialCodeGenerator) KeyspaceSize() float64 {
+	return math.Pow(float64(len(base62Alphabet)), float64(g.length))
+}
+
+// HashidsCodeGenerator maps row IDs to short, non-sequential looking codes in the style of Hashids/Sqids.
+// IDs are scrambled by an affine bijection over the code space of the current length and encoded with a
+// salt-shuffled alphabet, so distinct IDs always yield distinct codes.
+type HashidsCodeGenerator struct {
+	alphabet  string
+	minLength int
+	seed      [32]byte
+}
+
+// NewHashidsCodeGenerator creates an obfuscated-ID generator from an alphabet and salt.
+func NewHashidsCodeGenerator(alphabet, salt string, minLength int) (*HashidsCodeGenerator, error) {
+	if alphabet == "" {
+		alphabet = base62Alphabet
+	}
+
+	seen := make(map[rune]bool, len(alphabet))
+	for _, char := range alphabet {
+		if seen[char] {
+			return nil, fmt.Errorf("code alphabet contains duplicate character %q", char)
+		}
+		seen[char] = true
+	}
+
+	if len(alphabet) < 16 {
+		return nil, fmt.Errorf("code alphabet must contain at least 16 characters")
+	}
+
+	seed := sha256.Sum256([]byte(salt))
+
+	return &HashidsCodeGenerator{
+		alphabet:  shuffleAlphabet(alphabet, seed),
+		minLength: minLength,
+		seed:      seed,
+	}, nil
+}
+
+// Generate returns the obfuscated encoding of id.
+func (g *HashidsCodeGenerator) Generate(id int64, _ int) (string, error) {
+	if id <= 0 {
+		return "", fmt.Errorf("hashids codes require a positive id, got %d", id)
+	}
+
+	base := big.NewInt(int64(len(g.alphabet)))
+	value := big.NewInt(id)
+
+	length := g.minLength
+	space := new(big.Int).Exp(base, big.NewInt(int64(length)), nil)
+	for value.Cmp(space) >= 0 {
+		length++
+		space.Mul(space, base)
+	}
+
+	// The multiplier is coprime with the alphabet size and therefore with the whole space,
+	// which makes (id*multiplier + offset) mod space a bijection.
+	multiplier := new(big.Int).SetUint64(binary.BigEndian.Uint64(g.seed[:8]) | 1)
+	for new(big.Int).GCD(nil, nil, multiplier, base).Cmp(big.NewInt(1)) != 0 {
+		multiplier.Add(multiplier, big.NewInt(2))
+	}
+	offset := new(big.Int).SetUint64(binary.BigEndian.Uint64(g.seed[8:16]))
+
+	scrambled := new(big.Int).Mul(value, multiplier)
+	scrambled.Add(scrambled, offset)
+	scrambled.Mod(scrambled, space)
+
+	code := make([]byte, length)
+	remainder := new(big.Int)
+	for i := length - 1; i >= 0; i-- {
+		scrambled.DivMod(scrambled, base, remainder)
+		code[i] = g.alphabet[remainder.Int64()]
+	}
+
+	return string(code), nil
+}
+
+// UsesID reports true.
+func (g *HashidsCodeGenerator) UsesID() bool {
+	return true
+}
+
+// Length returns the minimum code length.
+func (g *HashidsCodeGenerator) Length() int {
+	return g.minLength
+}
+
+// KeyspaceSize returns len(alphabet)^minLength; later IDs produce longer codes.
+func (g *HashidsCodeGenerator) KeyspaceSize() float64 {
+	return math.Pow(float64(len(g.alphabet)), float64(g.minLength))
+}
+
+// WordCodeGenerator produces pronounceable codes such as "calm-otter-42". The number starts with two
+// digits and gains one whenever collisions become frequent.
+type WordCodeGenerator struct {
+	digits atomic.Int32
+}
+
+// NewWordCodeGenerator creates a word code generator with two-digit numbers.
+func NewWordCodeGenerator() *WordCodeGenerator {
+	g := &WordCodeGenerator{}
+	g.digits.Store(minWordCodeDigits)
+	return g
+}
+
+// Generate returns a random adjective-noun-number code, with one more digit for every few failed attempts.
+func (g *WordCodeGenerator) Generate(_ int64, attempt int) (string, error) {
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+
+	n := binary.BigEndian.Uint64(buf[:])
+	adjective := codeAdjectives[n%uint64(len(codeAdjectives))]
+	n /= uint64(len(codeAdjectives))
+	noun := codeNouns[n%uint64(len(codeNouns))]
+	n /= uint64(len(codeNouns))
+
+	digits := min(int(g.digits.Load())+attempt/attemptsPerLength, maxWordCodeDigits)
+
+	return fmt.Sprintf("%s-%s-%0*d", adjective, noun, digits, n%uint64(math.Pow10(digits))), nil
+}
+
+// UsesID reports false; word codes are independent of row IDs.
+func (g *WordCodeGenerator) UsesID() bool {
+	return false
+}
+
+// Length returns 0 because word codes vary in length.
+func (g *WordCodeGenerator) Length() int {
+	return 0
+}
+
+// KeyspaceSize returns the number of adjective, noun and number combinations at the current number of digits.
+func (g *WordCodeGenerator) KeyspaceSize() float64 {
+	return float64(len(codeAdjectives)*len(codeNouns)) * math.Pow10(int(g.digits.Load()))
+}
+
+// Grow adds a digit to the number, up to the column limit.
+func (g *WordCodeGenerator) Grow() bool {
+	for {
+		current := g.digits.Load()
+		if current >= maxWordCodeDigits {
+			return false
+		}
+		if g.digits.CompareAndSwap(current, current+1) {
+			return true
+		}
+	}
+}
+
+func encodeBase(value uint64, alphabet string) string {
+	base := uint64(len(alphabet))
+	if value == 0 {
+		return alphabet[:1]
+	}
+
+	var code []byte
+	for value > 0 {
+		code = append(code, alphabet[value%base])
+		value /= base
+	}
+
+	for i, j := 0, len(code)-1; i < j; i, j = i+1, j-1 {
+		code[i], code[j] = code[j], code[i]
+	}
+
+	return string(code)
+}
+
+// shuffleAlphabet deterministically permutes alphabet using a Fisher-Yates shuffle driven by seed.
+func shuffleAlphabet(alphabet string, seed [32]byte) string {
+	chars := []byte(alphabet)
+	state := seed
+
+	for i := len(chars) - 1; i > 0; i-- {
+		state = sha256.Sum256(state[:])
+		j := binary.BigEndian.Uint64(state[:8]) % uint64(i+1)
+		chars[i], chars[j] = chars[j], chars[i]
+	}
+
+	return string(chars)
+}
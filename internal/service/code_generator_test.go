@@ -0,0 +1,196 @@
+package service
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestHashidsCodeGeneratorIsBijective(t *testing.T) {
+	const alphabet = "0123456789abcdef"
+
+	tests := []struct {
+		name      string
+		salt      string
+		minLength int
+		maxID     int64
+	}{
+		{name: "grows from one to four characters", salt: "pepper", minLength: 1, maxID: 16 * 16 * 16 * 2},
+		{name: "grows from two to four characters", salt: "other salt", minLength: 2, maxID: 16 * 16 * 16 * 3},
+		{name: "no growth", salt: "", minLength: 4, maxID: 16 * 16 * 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewHashidsCodeGenerator(alphabet, tt.salt, tt.minLength)
+			if err != nil {
+				t.Fatalf("NewHashidsCodeGenerator: %v", err)
+			}
+
+			seen := make(map[string]int64, tt.maxID)
+			for id := int64(1); id <= tt.maxID; id++ {
+				code, err := g.Generate(id, 0)
+				if err != nil {
+					t.Fatalf("Generate(%d): %v", id, err)
+				}
+
+				// An ID needs as many digits as it has in base 16, and never fewer than minLength.
+				wantLength := max(tt.minLength, int(math.Floor(math.Log(float64(id))/math.Log(16)))+1)
+				if len(code) != wantLength {
+					t.Fatalf("Generate(%d) = %q, want %d characters", id, code, wantLength)
+				}
+				if strings.Trim(code, alphabet) != "" {
+					t.Fatalf("Generate(%d) = %q uses characters outside the alphabet", id, code)
+				}
+				if other, exists := seen[code]; exists {
+					t.Fatalf("Generate(%d) = %q, already generated for %d", id, code, other)
+				}
+				seen[code] = id
+
+				again, _ := g.Generate(id, 3)
+				if again != code {
+					t.Fatalf("Generate(%d) is not deterministic: %q then %q", id, code, again)
+				}
+			}
+		})
+	}
+}
+
+func TestHashidsCodeGeneratorDependsOnSalt(t *testing.T) {
+	first, err := NewHashidsCodeGenerator("", "first", 6)
+	if err != nil {
+		t.Fatalf("NewHashidsCodeGenerator: %v", err)
+	}
+	second, err := NewHashidsCodeGenerator("", "second", 6)
+	if err != nil {
+		t.Fatalf("NewHashidsCodeGenerator: %v", err)
+	}
+
+	same := 0
+	for id := int64(1); id <= 100; id++ {
+		a, _ := first.Generate(id, 0)
+		b, _ := second.Generate(id, 0)
+		if a == b {
+			same++
+		}
+	}
+	if same > 1 {
+		t.Errorf("%d of 100 codes are the same under different salts", same)
+	}
+}
+
+func TestNewHashidsCodeGeneratorRejectsBadAlphabets(t *testing.T) {
+	tests := []struct {
+		name     string
+		alphabet string
+	}{
+		{name: "duplicate character", alphabet: "0123456789abcdea"},
+		{name: "too short", alphabet: "0123456789"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewHashidsCodeGenerator(tt.alphabet, "salt", 6); err == nil {
+				t.Errorf("NewHashidsCodeGenerator(%q) succeeded, want an error", tt.alphabet)
+			}
+		})
+	}
+}
+
+func TestCodeGeneratorsRejectNonPositiveIDs(t *testing.T) {
+	hashids, err := NewHashidsCodeGenerator("", "salt", 6)
+	if err != nil {
+		t.Fatalf("NewHashidsCodeGenerator: %v", err)
+	}
+
+	for _, g := range []CodeGenerator{hashids, &SequentialCodeGenerator{length: 6}} {
+		for _, id := range []int64{0, -1} {
+			if code, err := g.Generate(id, 0); err == nil {
+				t.Errorf("%T.Generate(%d) = %q, want an error", g, id, code)
+			}
+		}
+	}
+}
+
+func TestRandomCodeGeneratorGrow(t *testing.T) {
+	g := NewRandomCodeGenerator(maxCodeLength - 2)
+
+	for _, want := range []bool{true, true, false} {
+		if got := g.Grow(); got != want {
+			t.Fatalf("Grow() = %v at length %d, want %v", got, g.Length(), want)
+		}
+	}
+	if g.Length() != maxCodeLength {
+		t.Errorf("length = %d, want %d", g.Length(), maxCodeLength)
+	}
+
+	code, err := g.Generate(0, 2*attemptsPerLength)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if len(code) != maxCodeLength {
+		t.Errorf("retry code has length %d, want it capped at %d", len(code), maxCodeLength)
+	}
+}
+
+func TestWordCodeGeneratorGrow(t *testing.T) {
+	g := NewWordCodeGenerator()
+	initial := g.KeyspaceSize()
+
+	for digits := minWordCodeDigits + 1; digits <= maxWordCodeDigits; digits++ {
+		if !g.Grow() {
+			t.Fatalf("Grow() = false at %d digits, want true", digits-1)
+		}
+		if got, want := g.KeyspaceSize(), initial*math.Pow10(digits-minWordCodeDigits); got != want {
+			t.Errorf("keyspace at %d digits = %v, want %v", digits, got, want)
+		}
+	}
+	if g.Grow() {
+		t.Errorf("Grow() = true at %d digits, want false", maxWordCodeDigits)
+	}
+
+	longest := 0
+	for _, words := range [][]string{codeAdjectives, codeNouns} {
+		width := 0
+		for _, word := range words {
+			width = max(width, len(word))
+		}
+		longest += width
+	}
+	if longest+2+maxWordCodeDigits > maxCodeLength {
+		t.Fatalf("longest word code has %d characters, want at most %d", longest+2+maxWordCodeDigits, maxCodeLength)
+	}
+
+	code, err := g.Generate(0, 2*attemptsPerLength)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if parts := strings.Split(code, "-"); len(parts) != 3 || len(parts[2]) != maxWordCodeDigits {
+		t.Errorf("retry code %q, want its number capped at %d digits", code, maxWordCodeDigits)
+	}
+}
+
+func TestWordCodeGeneratorRetriesAddDigits(t *testing.T) {
+	g := NewWordCodeGenerator()
+
+	tests := []struct {
+		attempt    int
+		wantDigits int
+	}{
+		{attempt: 0, wantDigits: minWordCodeDigits},
+		{attempt: attemptsPerLength - 1, wantDigits: minWordCodeDigits},
+		{attempt: attemptsPerLength, wantDigits: minWordCodeDigits + 1},
+		{attempt: 2 * attemptsPerLength, wantDigits: minWordCodeDigits + 2},
+	}
+
+	for _, tt := range tests {
+		code, err := g.Generate(0, tt.attempt)
+		if err != nil {
+			t.Fatalf("Generate: %v", err)
+		}
+		parts := strings.Split(code, "-")
+		if len(parts) != 3 || len(parts[2]) != tt.wantDigits {
+			t.Errorf("attempt %d: code %q, want an adjective, a noun and %d digits", tt.attempt, code, tt.wantDigits)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package service
+
+// codeAdjectives are the first words of pronounceable codes. Words are at most six letters so codes fit the 20 character limit.
+var codeAdjectives = []string{
+	"able", "agile", "amber", "ample", "azure", "bold", "brave", "brief", "brisk", "bright", "calm",
+	"candid", "cheery", "chief", "civil", "clean", "clear", "clever", "cosy", "crisp", "cubic",
+	"curly", "dapper", "daring", "deft", "eager", "early", "easy", "exact", "fair", "fancy", "fast",
+	"fine", "firm", "fluffy", "fond", "frank", "free", "fresh", "funny", "gentle", "giant", "glad",
+	"golden", "grand", "great", "happy", "hardy", "hearty", "honest", "humble", "ideal", "jolly",
+	"jovial", "juicy", "keen", "kind", "lively", "lucky", "mellow", "merry", "mighty", "modest",
+	"neat", "nimble", "noble", "opal", "polite", "proud", "quick", "quiet", "rapid", "rare", "ready",
+	"regal", "rosy", "royal", "rustic", "sage", "sharp", "shiny", "silent", "silky", "simple",
+	"sleek", "smart", "snappy", "solid", "sonic", "spry", "steady", "stout", "sunny", "super",
+	"sweet", "swift", "tidy", "tiny", "tough", "trusty", "upbeat", "urban", "vast", "vivid", "warm",
+	"wavy", "wise", "witty", "young", "zany", "zesty",
+}
+
+// codeNouns are the second words of pronounceable codes.
+var codeNouns = []string{
+	"acorn", "alpaca", "anchor", "apple", "badger", "beacon", "bear", "beaver", "birch", "bison",
+	"canyon", "cedar", "cobra", "comet", "condor", "coral", "cougar", "crane", "daisy", "dingo",
+	"dove", "eagle", "ember", "falcon", "fern", "finch", "fjord", "flint", "fox", "gecko", "geyser",
+	"goose", "grove", "harbor", "hawk", "heron", "hippo", "ibis", "iris", "jackal", "jaguar", "kayak",
+	"kiwi", "koala", "lagoon", "lemur", "lily", "lion", "lotus", "lynx", "magpie", "mango", "maple",
+	"marten", "meadow", "mesa", "moose", "newt", "oak", "ocelot", "orca", "osprey", "otter", "owl",
+	"panda", "parrot", "pebble", "pecan", "pine", "plover", "pony", "prism", "puffin", "quail",
+	"quartz", "raven", "reef", "robin", "salmon", "seal", "shark", "sloth", "spruce", "squid",
+	"stork", "swan", "tapir", "tiger", "toucan", "trout", "tulip", "turtle", "valley", "viper",
+	"walrus", "willow", "wolf", "wombat", "yak", "zebra",
+}
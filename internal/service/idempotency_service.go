@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/config"
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/edson-mazvila/url-shortener/internal/repository"
+)
+
+const (
+	// maxIdempotencyKeyLength matches the idempotency_keys.key column.
+	maxIdempotencyKeyLength = 255
+	// idempotencyLockTimeout is how long an unfinished request holds its key before a retry may take it over.
+	idempotencyLockTimeout = time.Minute
+)
+
+// IdempotencyService stores and replays responses of requests sent with an Idempotency-Key header.
+type IdempotencyService struct {
+	store  repository.IdempotencyStore
+	config *config.URLConfig
+	logger *slog.Logger
+}
+
+// NewIdempotencyService creates a new idempotency service.
+func NewIdempotencyService(store repository.IdempotencyStore, cfg *config.URLConfig, logger *slog.Logger) *IdempotencyService {
+	return &IdempotencyService{
+		store:  store,
+		config: cfg,
+		logger: logger,
+	}
+}
+
+// Begin reserves key for a request identified by requestHash. Keys are scoped to apiKeyID, the API key
+// that sent the request, so clients never see each other's keys. It returns the stored record when the
+// request has already completed and should be replayed, or nil when the caller should process it.
+func (s *IdempotencyService) Begin(ctx context.Context, apiKeyID int64, key, requestHash string) (*domain.IdempotencyRecord, error) {
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return nil, domain.ErrInvalidIdempotencyKey
+	}
+
+	now := time.Now()
+	record := &domain.IdempotencyRecord{
+		APIKeyID:    apiKeyID,
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.config.IdempotencyTTL),
+	}
+
+	err := s.store.CreateIdempotencyKey(ctx, record, now.Add(-idempotencyLockTimeout))
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, domain.ErrIdempotencyKeyExists) {
+		return nil, err
+	}
+
+	existing, err := s.store.GetIdempotencyKey(ctx, apiKeyID, key)
+	if err != nil {
+		if errors.Is(err, domain.ErrIdempotencyKeyNotFound) {
+			// The key was released between the two queries; the client can simply retry.
+			return nil, domain.ErrIdempotencyKeyInProgress
+		}
+		return nil, err
+	}
+
+	if existing.RequestHash != requestHash {
+		return nil, domain.ErrIdempotencyKeyReused
+	}
+
+	if !existing.Completed() {
+		return nil, domain.ErrIdempotencyKeyInProgress
+	}
+
+	s.logger.Debug("replaying idempotent response",
+		slog.Int64("api_key_id", apiKeyID),
+		slog.String("idempotency_key", key),
+	)
+
+	return existing, nil
+}
+
+// Complete stores the response to replay for key of apiKeyID.
+func (s *IdempotencyService) Complete(ctx context.Context, apiKeyID int64, key string, statusCode int, body []byte) error {
+	if err := s.store.CompleteIdempotencyKey(ctx, apiKeyID, key, statusCode, body); err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+
+	return nil
+}
+
+// Release frees key of apiKeyID so a failed request can be retried with it.
+func (s *IdempotencyService) Release(ctx context.Context, apiKeyID int64, key string) error {
+	if err := s.store.DeleteIdempotencyKey(ctx, apiKeyID, key); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// CleanupExpired removes all expired idempotency keys.
+func (s *IdempotencyService) CleanupExpired(ctx context.Context) (int64, error) {
+	count, err := s.store.DeleteExpiredIdempotencyKeys(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to cleanup expired idempotency keys: %w", err)
+	}
+
+	return count, nil
+}
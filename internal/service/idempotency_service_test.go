@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/config"
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+)
+
+func TestIdempotencyServiceScopesKeysToAPIKeys(t *testing.T) {
+	const (
+		key       = "create-campaign-link"
+		firstHash = "1111111111111111111111111111111111111111111111111111111111111111"
+		otherHash = "2222222222222222222222222222222222222222222222222222222222222222"
+	)
+
+	for _, backend := range testBackends(t) {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc := NewIdempotencyService(backend.idempotency, &config.URLConfig{IdempotencyTTL: time.Hour}, slog.New(slog.DiscardHandler))
+
+			if record, err := svc.Begin(ctx, 1, key, firstHash); err != nil || record != nil {
+				t.Fatalf("Begin for key 1: got %v, %v, want a fresh reservation", record, err)
+			}
+			if err := svc.Complete(ctx, 1, key, http.StatusCreated, []byte(`{"short_code":"first"}`)); err != nil {
+				t.Fatalf("Complete: %v", err)
+			}
+
+			steps := []struct {
+				name       string
+				apiKeyID   int64
+				hash       string
+				wantErr    error
+				wantReplay bool
+			}{
+				{name: "same key and body replays", apiKeyID: 1, hash: firstHash, wantReplay: true},
+				{name: "same key with another body", apiKeyID: 1, hash: otherHash, wantErr: domain.ErrIdempotencyKeyReused},
+				{name: "another api key with another body", apiKeyID: 2, hash: otherHash},
+				{name: "another api key while in progress", apiKeyID: 2, hash: otherHash, wantErr: domain.ErrIdempotencyKeyInProgress},
+				{name: "bootstrap key with the same body", apiKeyID: 0, hash: firstHash},
+			}
+
+			for _, step := range steps {
+				record, err := svc.Begin(ctx, step.apiKeyID, key, step.hash)
+				if !errors.Is(err, step.wantErr) {
+					t.Errorf("%s: got err %v, want %v", step.name, err, step.wantErr)
+					continue
+				}
+				if replayed := record != nil; replayed != step.wantReplay {
+					t.Errorf("%s: replayed = %v, want %v", step.name, replayed, step.wantReplay)
+				}
+			}
+
+			if err := svc.Release(ctx, 2, key); err != nil {
+				t.Fatalf("Release: %v", err)
+			}
+			record, err := svc.Begin(ctx, 1, key, firstHash)
+			if err != nil || record == nil || string(record.ResponseBody) != `{"short_code":"first"}` {
+				t.Errorf("after another api key released the key: got %v, %v, want the stored response", record, err)
+			}
+		})
+	}
+}
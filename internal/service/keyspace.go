@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+const (
+	// collisionWindow is the number of recent insert attempts the collision rate is computed over.
+	collisionWindow = 200
+	// collisionGrowThreshold is the recent collision rate at which the base code length grows.
+	collisionGrowThreshold = 0.2
+)
+
+// KeyspaceStats reports how much of the short code space is in use.
+type KeyspaceStats struct {
+	Strategy         string  `json:"strategy"`
+	ConfiguredLength int     `json:"configured_length"`
+	CurrentLength    int     `json:"current_length"`
+	KeyspaceSize     float64 `json:"keyspace_size"`
+	UsedCodes        int64   `json:"used_codes"`
+	Utilisation      float64 `json:"utilisation"`
+	Attempts         uint64  `json:"attempts"`
+	Collisions       uint64  `json:"collisions"`
+	CollisionRate    float64 `json:"collision_rate"`
+	RecentRate       float64 `json:"recent_collision_rate"`
+}
+
+// collisionTracker records the outcome of insert attempts for generated codes.
+type collisionTracker struct {
+	mu         sync.Mutex
+	attempts   uint64
+	collisions uint64
+	window     [collisionWindow]bool
+	filled     int
+	next       int
+	recent     int
+}
+
+// record stores the outcome of one attempt and returns the collision rate over the recent window,
+// or 0 until the window is full.
+func (t *collisionTracker) record(collided bool) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.attempts++
+	if collided {
+		t.collisions++
+	}
+
+	if t.filled == collisionWindow && t.window[t.next] {
+		t.recent--
+	}
+	t.window[t.next] = collided
+	if collided {
+		t.recent++
+	}
+	t.next = (t.next + 1) % collisionWindow
+	if t.filled < collisionWindow {
+		t.filled++
+	}
+
+	if t.filled < collisionWindow {
+		return 0
+	}
+	return float64(t.recent) / float64(t.filled)
+}
+
+// reset clears the recent window, e.g. after the code length has grown.
+func (t *collisionTracker) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.window = [collisionWindow]bool{}
+	t.filled, t.next, t.recent = 0, 0, 0
+}
+
+func (t *collisionTracker) snapshot() (attempts, collisions uint64, recentRate float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.filled > 0 {
+		recentRate = float64(t.recent) / float64(t.filled)
+	}
+	return t.attempts, t.collisions, recentRate
+}
+
+// KeyspaceStats reports short code space utilisation and collision counters.
+// Used codes include custom codes, so utilisation is an upper bound.
+func (s *URLService) KeyspaceStats(ctx context.Context) (*KeyspaceStats, error) {
+	used, err := s.repo.Count(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count urls: %w", err)
+	}
+
+	attempts, collisions, recentRate := s.collisions.snapshot()
+
+	stats := &KeyspaceStats{
+		Strategy:         s.config.CodeStrategy,
+		ConfiguredLength: s.config.ShortCodeLength,
+		CurrentLength:    s.codes.Length(),
+		KeyspaceSize:     s.codes.KeyspaceSize(),
+		UsedCodes:        used,
+		Attempts:         attempts,
+		Collisions:       collisions,
+		RecentRate:       recentRate,
+	}
+
+	if stats.KeyspaceSize > 0 {
+		stats.Utilisation = float64(used) / stats.KeyspaceSize
+	}
+	if attempts > 0 {
+		stats.CollisionRate = float64(collisions) / float64(attempts)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestCollisionTracker(t *testing.T) {
+	tests := []struct {
+		name string
+		// outcomes are recorded in order; true is a collision.
+		outcomes       func() []bool
+		wantRate       float64
+		wantAttempts   uint64
+		wantCollisions uint64
+	}{
+		{
+			name:     "window not full",
+			outcomes: func() []bool { return repeatOutcome(true, collisionWindow-1) },
+			// The rate stays 0 until there are enough attempts to judge it.
+			wantRate:       0,
+			wantAttempts:   collisionWindow - 1,
+			wantCollisions: collisionWindow - 1,
+		},
+		{
+			name: "full window",
+			outcomes: func() []bool {
+				return append(repeatOutcome(true, collisionWindow/4), repeatOutcome(false, collisionWindow*3/4)...)
+			},
+			wantRate:       0.25,
+			wantAttempts:   collisionWindow,
+			wantCollisions: collisionWindow / 4,
+		},
+		{
+			name: "old collisions slide out",
+			outcomes: func() []bool {
+				return append(repeatOutcome(true, collisionWindow), repeatOutcome(false, collisionWindow)...)
+			},
+			wantRate:       0,
+			wantAttempts:   2 * collisionWindow,
+			wantCollisions: collisionWindow,
+		},
+		{
+			name: "partially slid window",
+			outcomes: func() []bool {
+				return append(repeatOutcome(true, collisionWindow), repeatOutcome(false, collisionWindow/2)...)
+			},
+			wantRate:       0.5,
+			wantAttempts:   collisionWindow * 3 / 2,
+			wantCollisions: collisionWindow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tracker collisionTracker
+			var rate float64
+			for _, collided := range tt.outcomes() {
+				rate = tracker.record(collided)
+			}
+
+			if rate != tt.wantRate {
+				t.Errorf("rate = %v, want %v", rate, tt.wantRate)
+			}
+
+			attempts, collisions, _ := tracker.snapshot()
+			if attempts != tt.wantAttempts || collisions != tt.wantCollisions {
+				t.Errorf("snapshot = %d attempts, %d collisions, want %d, %d", attempts, collisions, tt.wantAttempts, tt.wantCollisions)
+			}
+		})
+	}
+}
+
+func TestCollisionTrackerReset(t *testing.T) {
+	var tracker collisionTracker
+	for _, collided := range repeatOutcome(true, collisionWindow) {
+		tracker.record(collided)
+	}
+
+	tracker.reset()
+
+	attempts, collisions, recent := tracker.snapshot()
+	if attempts != collisionWindow || collisions != collisionWindow {
+		t.Errorf("reset cleared the totals: %d attempts, %d collisions", attempts, collisions)
+	}
+	if recent != 0 {
+		t.Errorf("recent rate after reset = %v, want 0", recent)
+	}
+	if rate := tracker.record(true); rate != 0 {
+		t.Errorf("rate right after reset = %v, want 0 until the window refills", rate)
+	}
+}
+
+func TestRecordCollisionGrowsCodeLength(t *testing.T) {
+	codes := NewRandomCodeGenerator(6)
+	svc := &URLService{codes: codes, logger: slog.New(slog.DiscardHandler)}
+
+	// A rate just below the threshold leaves the length alone.
+	below := int(collisionGrowThreshold*collisionWindow) - 1
+	for _, collided := range append(repeatOutcome(true, below), repeatOutcome(false, collisionWindow-below)...) {
+		svc.recordCollision(collided)
+	}
+	if codes.Length() != 6 {
+		t.Fatalf("length = %d below the threshold, want 6", codes.Length())
+	}
+
+	// Collisions replace the oldest outcomes of the full window until the rate reaches the threshold.
+	for i := 0; i < collisionWindow && codes.Length() == 6; i++ {
+		svc.recordCollision(true)
+	}
+	if codes.Length() != 7 {
+		t.Errorf("length = %d after frequent collisions, want 7", codes.Length())
+	}
+	if _, _, recent := svc.collisions.snapshot(); recent != 0 {
+		t.Errorf("recent rate after growing = %v, want the window reset", recent)
+	}
+}
+
+func TestRecordCollisionGrowsWordCodes(t *testing.T) {
+	codes := NewWordCodeGenerator()
+	svc := &URLService{codes: codes, logger: slog.New(slog.DiscardHandler)}
+	initial := codes.KeyspaceSize()
+
+	for i := 0; i < collisionWindow && codes.KeyspaceSize() == initial; i++ {
+		svc.recordCollision(true)
+	}
+	if got := codes.KeyspaceSize(); got != 10*initial {
+		t.Errorf("keyspace = %v after frequent collisions, want %v", got, 10*initial)
+	}
+
+	code, err := codes.Generate(0, 0)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if parts := strings.Split(code, "-"); len(parts) != 3 || len(parts[2]) != minWordCodeDigits+1 {
+		t.Errorf("code %q after growing, want a %d digit number", code, minWordCodeDigits+1)
+	}
+}
+
+func repeatOutcome(collided bool, n int) []bool {
+	outcomes := make([]bool, n)
+	for i := range outcomes {
+		outcomes[i] = collided
+	}
+	return outcomes
+}
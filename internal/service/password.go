@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// maxPasswordLength is the longest password bcrypt hashes without truncating.
+	maxPasswordLength = 72
+	// maxTrackedPasswordClients bounds the attempt windows kept in memory before expired ones are swept.
+	maxTrackedPasswordClients = 10000
+)
+
+// hashPassword validates password and returns its bcrypt hash.
+func hashPassword(password string) (string, error) {
+	if password == "" || len(password) > maxPasswordLength {
+		return "", fmt.Errorf("password must be 1-%d bytes: %w", maxPasswordLength, domain.ErrInvalidPassword)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	return string(hash), nil
+}
+
+// checkPassword verifies the password of a visit to a protected link, throttling clients that keep
+// entering wrong ones. Public links always pass.
+func (s *URLService) checkPassword(urlEntity *domain.URL, visit Visit) error {
+	if !urlEntity.IsPasswordProtected() {
+		return nil
+	}
+
+	if visit.Password == "" {
+		return domain.ErrPasswordRequired
+	}
+
+	// The attempt is counted before the comparison so that parallel guesses cannot all pass the
+	// check while bcrypt is still busy with the first of them.
+	key := urlEntity.ShortCode + "|" + visit.Click.IPAddress
+	if !s.attempts.reserve(key) {
+		return domain.ErrTooManyPasswordAttempts
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(urlEntity.PasswordHash), []byte(visit.Password))
+	if err != nil {
+		if !errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return fmt.Errorf("failed to verify password: %w", err)
+		}
+		return domain.ErrIncorrectPassword
+	}
+
+	s.attempts.reset(key)
+
+	return nil
+}
+
+// attemptLimiter counts password attempts per key and blocks a key for the rest of its window once it
+// reaches the limit. The window starts at the first attempt, and a successful attempt clears it.
+type attemptLimiter struct {
+	maxAttempts int
+	window      time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*attemptWindow
+}
+
+type attemptWindow struct {
+	attempts int
+	resetAt  time.Time
+}
+
+func newAttemptLimiter(maxAttempts int, window time.Duration) *attemptLimiter {
+	return &attemptLimiter{
+		maxAttempts: maxAttempts,
+		window:      window,
+		entries:     make(map[string]*attemptWindow),
+	}
+}
+
+// reserve records an attempt by key and reports whether it is within the limit. Checking and counting
+// happen under one lock, so concurrent attempts cannot exceed the limit; refused attempts are not
+// counted and do not extend the window.
+func (l *attemptLimiter) reserve(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	entry, ok := l.entries[key]
+	if !ok || now.After(entry.resetAt) {
+		if len(l.entries) >= maxTrackedPasswordClients {
+			l.sweep(now)
+		}
+		entry = &attemptWindow{resetAt: now.Add(l.window)}
+		l.entries[key] = entry
+	}
+
+	if entry.attempts >= l.maxAttempts {
+		return false
+	}
+	entry.attempts++
+
+	return true
+}
+
+// reset forgets the attempts of key.
+func (l *attemptLimiter) reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.entries, key)
+}
+
+// sweep drops expired windows. The caller must hold l.mu.
+func (l *attemptLimiter) sweep(now time.Time) {
+	for key, entry := range l.entries {
+		if now.After(entry.resetAt) {
+			delete(l.entries, key)
+		}
+	}
+}
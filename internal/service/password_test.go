@@ -0,0 +1,177 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+)
+
+func TestAttemptLimiter(t *testing.T) {
+	tests := []struct {
+		name string
+		// run makes attempts with key "client" and returns whether its last attempt was allowed.
+		run  func(l *attemptLimiter) bool
+		want bool
+	}{
+		{
+			name: "unknown client",
+			run:  func(l *attemptLimiter) bool { return l.reserve("client") },
+			want: true,
+		},
+		{
+			name: "last attempt within the limit",
+			run: func(l *attemptLimiter) bool {
+				reserveTimes(l, "client", 2)
+				return l.reserve("client")
+			},
+			want: true,
+		},
+		{
+			name: "over the limit",
+			run: func(l *attemptLimiter) bool {
+				reserveTimes(l, "client", 3)
+				return l.reserve("client")
+			},
+			want: false,
+		},
+		{
+			name: "reset after success",
+			run: func(l *attemptLimiter) bool {
+				reserveTimes(l, "client", 3)
+				l.reset("client")
+				return l.reserve("client")
+			},
+			want: true,
+		},
+		{
+			name: "window expired",
+			run: func(l *attemptLimiter) bool {
+				reserveTimes(l, "client", 3)
+				time.Sleep(60 * time.Millisecond)
+				return l.reserve("client")
+			},
+			want: true,
+		},
+		{
+			name: "window starts at the first attempt",
+			run: func(l *attemptLimiter) bool {
+				reserveTimes(l, "client", 1)
+				time.Sleep(30 * time.Millisecond)
+				reserveTimes(l, "client", 2)
+				time.Sleep(30 * time.Millisecond)
+				return l.reserve("client")
+			},
+			want: true,
+		},
+		{
+			name: "refused attempts do not extend the window",
+			run: func(l *attemptLimiter) bool {
+				reserveTimes(l, "client", 3)
+				time.Sleep(30 * time.Millisecond)
+				reserveTimes(l, "client", 2)
+				time.Sleep(30 * time.Millisecond)
+				return l.reserve("client")
+			},
+			want: true,
+		},
+		{
+			name: "other clients are independent",
+			run: func(l *attemptLimiter) bool {
+				reserveTimes(l, "other", 4)
+				return l.reserve("client")
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := newAttemptLimiter(3, 50*time.Millisecond)
+			if got := tt.run(limiter); got != tt.want {
+				t.Errorf("reserve = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttemptLimiterConcurrentAttempts(t *testing.T) {
+	limiter := newAttemptLimiter(3, time.Minute)
+
+	var (
+		wg      sync.WaitGroup
+		allowed atomic.Int64
+	)
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if limiter.reserve("client") {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := allowed.Load(); got != 3 {
+		t.Errorf("%d concurrent attempts allowed, want 3", got)
+	}
+}
+
+func TestAttemptLimiterSweepsExpiredWindows(t *testing.T) {
+	limiter := newAttemptLimiter(3, time.Millisecond)
+	for i := range maxTrackedPasswordClients {
+		limiter.reserve(strconv.Itoa(i))
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	limiter.reserve("newcomer")
+
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	if len(limiter.entries) != 1 {
+		t.Errorf("tracked %d clients after the sweep, want 1", len(limiter.entries))
+	}
+}
+
+func TestHashPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  error
+	}{
+		{name: "valid", password: "correct horse"},
+		{name: "longest", password: strings.Repeat("p", maxPasswordLength)},
+		{name: "empty", password: "", wantErr: domain.ErrInvalidPassword},
+		{name: "too long", password: strings.Repeat("p", maxPasswordLength+1), wantErr: domain.ErrInvalidPassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := hashPassword(tt.password)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got err %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("hashPassword: %v", err)
+			}
+			if hash == tt.password || !strings.HasPrefix(hash, "$2") {
+				t.Errorf("hash %q is not a bcrypt hash", hash)
+			}
+		})
+	}
+}
+
+func reserveTimes(l *attemptLimiter, key string, n int) {
+	for range n {
+		l.reserve(key)
+	}
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/edson-mazvila/url-shortener/internal/repository"
+	"github.com/edson-mazvila/url-shortener/internal/useragent"
+)
+
+// Visit describes a request to follow a short link.
+type Visit struct {
+	// Path is the escaped part of the request path after the short code, without the leading slash.
+	Path  string
+	Query url.Values
+	// Variant is the variant the visitor was sent to before, if the link's variants are sticky, or was
+	// shown on the preview page they continued from.
+	Variant string
+	// Password is the password entered for a protected link, empty when none was. Entering it also
+	// confirms the visit, so protected links are never previewed.
+	Password string
+	// Preview asks for a preview of the destination instead of a redirect.
+	Preview bool
+	// Confirmed reports that the visitor continued from the preview page.
+	Confirmed bool
+	Click     *domain.Click
+}
+
+// Redirect is where a visit is sent.
+type Redirect struct {
+	URL      *domain.URL
+	Location string
+	Status   int
+	// CacheMaxAge is how long clients may cache the redirect; 0 disables caching.
+	CacheMaxAge time.Duration
+	// TargetRule names the targeting rule that chose the destination, if any.
+	TargetRule string
+	// Variant names the variant that chose the destination, if any.
+	Variant string
+	// StickyFor is how long the visitor should keep being sent to Variant; 0 when variants are not sticky.
+	StickyFor time.Duration
+	// Personalized reports whether other visitors may be sent elsewhere, so the redirect must not be
+	// cached by shared caches.
+	Personalized bool
+	// Preview reports that the visitor should be shown Location instead of being sent there. The visit
+	// has not been counted.
+	Preview bool
+	// Fallback reports that the link is unavailable and Location is its fallback URL. The visit has not
+	// been counted.
+	Fallback bool
+}
+
+// ResolveRedirect looks up shortCode, records the visit and returns where to send the visitor. Visits
+// that ask for a preview, and unconfirmed visits of links that are always previewed, are resolved
+// without being recorded. Visitors of unavailable links are sent to the link's fallback URL if it has
+// one.
+func (s *URLService) ResolveRedirect(ctx context.Context, shortCode string, visit Visit) (*Redirect, error) {
+	urlEntity, err := s.repo.GetByShortCode(repository.AllowCached(ctx), shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAvailable(urlEntity); err != nil {
+		return fallback(urlEntity, err)
+	}
+
+	if visit.Path != "" && !urlEntity.PathPassthrough {
+		return nil, domain.ErrURLNotFound
+	}
+
+	if err := s.checkPassword(urlEntity, visit); err != nil {
+		return nil, err
+	}
+
+	location := s.geo.Lookup(visit.Click.IPAddress)
+
+	destination := urlEntity.OriginalURL
+	var targetRule string
+	if len(urlEntity.TargetingRules) > 0 {
+		visitor := domain.Visitor{
+			Info:    useragent.Parse(visit.Click.UserAgent),
+			Country: location.Country,
+			Region:  location.Region,
+		}
+		if rule := matchTargetingRule(urlEntity.TargetingRules, visitor); rule != nil {
+			destination = rule.URL
+			targetRule = rule.Name
+		}
+	}
+
+	var variantName string
+	var stickyFor time.Duration
+	if targetRule == "" {
+		var sticky string
+		if urlEntity.StickyVariants || visit.Confirmed {
+			sticky = visit.Variant
+		}
+		if variant := pickVariant(urlEntity.Variants, sticky); variant != nil {
+			destination = variant.URL
+			variantName = variant.Name
+			if urlEntity.StickyVariants {
+				stickyFor = s.config.VariantCookieTTL
+			}
+		}
+	}
+
+	redirectURL, err := s.buildLocation(urlEntity, destination, visit)
+	if err != nil {
+		return nil, err
+	}
+
+	if visit.Password == "" && (visit.Preview || (urlEntity.AlwaysPreview && !visit.Confirmed)) {
+		return &Redirect{
+			URL:          urlEntity,
+			Location:     redirectURL,
+			TargetRule:   targetRule,
+			Variant:      variantName,
+			Personalized: len(urlEntity.TargetingRules) > 0 || len(urlEntity.Variants) > 0,
+			Preview:      true,
+		}, nil
+	}
+
+	if urlEntity.MaxClicks != nil {
+		if err := s.repo.ConsumeClick(ctx, urlEntity); err != nil {
+			if errors.Is(err, domain.ErrClickLimitReached) {
+				s.logger.Info("click limit reached", slog.String("short_code", shortCode))
+				return fallback(urlEntity, err)
+			}
+			return nil, err
+		}
+	}
+
+	urlEntity.IncrementAccessCount()
+	s.clicks.Record(shortCode, *urlEntity.LastAccessed)
+
+	click := visit.Click
+	click.URLID = urlEntity.ID
+	click.ShortCode = shortCode
+	click.ClickedAt = *urlEntity.LastAccessed
+	click.TargetRule = targetRule
+	click.Country = location.Country
+	click.Variant = variantName
+	s.recorder.Enqueue(click)
+
+	status, maxAge := s.redirectPolicy(urlEntity)
+
+	return &Redirect{
+		URL:          urlEntity,
+		Location:     redirectURL,
+		Status:       status,
+		CacheMaxAge:  maxAge,
+		TargetRule:   targetRule,
+		Variant:      variantName,
+		StickyFor:    stickyFor,
+		Personalized: len(urlEntity.TargetingRules) > 0 || len(urlEntity.Variants) > 0,
+	}, nil
+}
+
+// checkAvailable returns why urlEntity cannot be visited right now, or nil if it can.
+func (s *URLService) checkAvailable(urlEntity *domain.URL) error {
+	if urlEntity.IsDisabled() {
+		return domain.ErrURLDisabled
+	}
+
+	if urlEntity.IsExpired() {
+		s.logger.Warn("attempted to access expired url",
+			slog.String("short_code", urlEntity.ShortCode),
+		)
+		return domain.ErrURLExpired
+	}
+
+	if !urlEntity.IsActive() {
+		return domain.ErrURLNotActive
+	}
+
+	if urlEntity.IsExhausted() {
+		return domain.ErrClickLimitReached
+	}
+
+	return nil
+}
+
+// fallback sends the visitor of an unavailable link to its fallback URL, or fails with err if the link
+// has none. Fallback redirects are temporary and never cached, as the link may become available again.
+func fallback(urlEntity *domain.URL, err error) (*Redirect, error) {
+	if urlEntity.FallbackURL == "" {
+		return nil, err
+	}
+
+	return &Redirect{
+		URL:      urlEntity,
+		Location: urlEntity.FallbackURL,
+		Status:   http.StatusFound,
+		Fallback: true,
+	}, nil
+}
+
+// buildLocation applies the link's path and query passthrough options to destination.
+func (s *URLService) buildLocation(urlEntity *domain.URL, rawDestination string, visit Visit) (string, error) {
+	passQuery := urlEntity.QueryPassthrough && len(visit.Query) > 0
+	if visit.Path == "" && !passQuery {
+		return rawDestination, nil
+	}
+
+	destination, err := url.Parse(rawDestination)
+	if err != nil {
+		return "", domain.ErrInvalidURL
+	}
+
+	if visit.Path != "" {
+		segments := strings.Split(visit.Path, "/")
+		for _, segment := range segments {
+			// Dot segments, escaped or not, would let a visit climb above the destination path.
+			if unescaped, err := url.PathUnescape(segment); err != nil || unescaped == "." || unescaped == ".." {
+				return "", domain.ErrURLNotFound
+			}
+		}
+		destination = destination.JoinPath(segments...)
+	}
+
+	if passQuery {
+		precedence := urlEntity.QueryPrecedence
+		if precedence == "" {
+			precedence = s.config.QueryPrecedence
+		}
+
+		query := destination.Query()
+		for key, values := range visit.Query {
+			if _, exists := query[key]; exists && precedence == domain.QueryPrecedenceDestination {
+				continue
+			}
+			query[key] = values
+		}
+		destination.RawQuery = query.Encode()
+	}
+
+	return destination.String(), nil
+}
+
+// redirectPolicy returns the status code to redirect to urlEntity with and how long clients may cache
+// the redirect. Only permanent redirects of public links without a click limit are cacheable, and never
+// beyond the link's expiry.
+func (s *URLService) redirectPolicy(urlEntity *domain.URL) (int, time.Duration) {
+	status := urlEntity.RedirectType
+	if status == 0 {
+		status = s.config.DefaultRedirectType
+	}
+
+	if (status != http.StatusMovedPermanently && status != http.StatusPermanentRedirect) || urlEntity.IsPasswordProtected() || urlEntity.MaxClicks != nil {
+		return status, 0
+	}
+
+	maxAge := s.config.RedirectCacheMaxAge
+	if urlEntity.ExpiresAt != nil {
+		maxAge = min(maxAge, max(time.Until(*urlEntity.ExpiresAt), 0))
+	}
+
+	return status, maxAge
+}
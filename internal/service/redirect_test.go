@@ -0,0 +1,384 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/edson-mazvila/url-shortener/internal/useragent"
+)
+
+const iPhoneUserAgent = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1"
+
+func TestResolveRedirectOrder(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	mobileRule := []domain.TargetingRule{{Name: "ios", OS: useragent.OSiOS, URL: "https://example.com/ios"}}
+	variants := []domain.Variant{
+		{Name: "a", URL: "https://example.com/a"},
+		{Name: "b", URL: "https://example.com/b"},
+	}
+
+	tests := []struct {
+		name string
+		opts CreateURLOptions
+		// setup changes the stored link after it was created.
+		setup        func(t *testing.T, svc *URLService, code string)
+		visit        Visit
+		wantErr      error
+		wantLocation string
+		wantFallback bool
+		wantPreview  bool
+		wantRule     string
+		wantVariant  string
+		wantCounted  bool
+	}{
+		{
+			name:         "plain redirect",
+			wantLocation: "https://example.com/original",
+			wantCounted:  true,
+		},
+		{
+			name:         "fallback before password",
+			opts:         CreateURLOptions{FallbackURL: "https://example.com/gone", Password: "secret"},
+			setup:        disableURL,
+			wantLocation: "https://example.com/gone",
+			wantFallback: true,
+		},
+		{
+			name:    "disabled without fallback",
+			setup:   disableURL,
+			wantErr: domain.ErrURLDisabled,
+		},
+		{
+			name: "expired without fallback",
+			opts: CreateURLOptions{TTL: time.Hour},
+			setup: func(t *testing.T, svc *URLService, code string) {
+				expireURL(t, svc, code, past)
+			},
+			wantErr: domain.ErrURLExpired,
+		},
+		{
+			name:    "not active yet",
+			opts:    CreateURLOptions{ActivatesAt: &future},
+			wantErr: domain.ErrURLNotActive,
+		},
+		{
+			name:    "path without passthrough",
+			visit:   Visit{Path: "extra"},
+			wantErr: domain.ErrURLNotFound,
+		},
+		{
+			name:    "password before preview",
+			opts:    CreateURLOptions{Password: "secret", AlwaysPreview: true},
+			visit:   Visit{Preview: true},
+			wantErr: domain.ErrPasswordRequired,
+		},
+		{
+			name:    "wrong password",
+			opts:    CreateURLOptions{Password: "secret"},
+			visit:   Visit{Password: "guess"},
+			wantErr: domain.ErrIncorrectPassword,
+		},
+		{
+			name:         "password skips the preview",
+			opts:         CreateURLOptions{Password: "secret", AlwaysPreview: true},
+			visit:        Visit{Password: "secret"},
+			wantLocation: "https://example.com/original",
+			wantCounted:  true,
+		},
+		{
+			name:         "targeting before variants",
+			opts:         CreateURLOptions{TargetingRules: mobileRule, Variants: variants, StickyVariants: true},
+			visit:        Visit{Variant: "b", Click: &domain.Click{UserAgent: iPhoneUserAgent}},
+			wantLocation: "https://example.com/ios",
+			wantRule:     "ios",
+			wantCounted:  true,
+		},
+		{
+			name:         "sticky variant when no rule matches",
+			opts:         CreateURLOptions{TargetingRules: mobileRule, Variants: variants, StickyVariants: true},
+			visit:        Visit{Variant: "b", Click: &domain.Click{UserAgent: "curl/8.0"}},
+			wantLocation: "https://example.com/b",
+			wantVariant:  "b",
+			wantCounted:  true,
+		},
+		{
+			name:         "previewed variant is kept after confirmation",
+			opts:         CreateURLOptions{Variants: variants, AlwaysPreview: true},
+			visit:        Visit{Variant: "a", Confirmed: true},
+			wantLocation: "https://example.com/a",
+			wantVariant:  "a",
+			wantCounted:  true,
+		},
+		{
+			name:         "always preview",
+			opts:         CreateURLOptions{AlwaysPreview: true, MaxClicks: 1},
+			wantLocation: "https://example.com/original",
+			wantPreview:  true,
+		},
+		{
+			name:         "requested preview",
+			visit:        Visit{Preview: true},
+			wantLocation: "https://example.com/original",
+			wantPreview:  true,
+		},
+		{
+			name: "exhausted click limit with fallback",
+			opts: CreateURLOptions{MaxClicks: 1, FallbackURL: "https://example.com/sold-out"},
+			setup: func(t *testing.T, svc *URLService, code string) {
+				if _, err := svc.ResolveRedirect(context.Background(), code, testVisit(Visit{})); err != nil {
+					t.Fatalf("first visit: %v", err)
+				}
+			},
+			wantLocation: "https://example.com/sold-out",
+			wantFallback: true,
+		},
+		{
+			name: "exhausted click limit without fallback",
+			opts: CreateURLOptions{MaxClicks: 1},
+			setup: func(t *testing.T, svc *URLService, code string) {
+				if _, err := svc.ResolveRedirect(context.Background(), code, testVisit(Visit{})); err != nil {
+					t.Fatalf("first visit: %v", err)
+				}
+			},
+			wantErr: domain.ErrClickLimitReached,
+		},
+	}
+
+	for _, backend := range testBackends(t) {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc := newTestURLService(t, backend)
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					created, _, err := svc.CreateShortURL(ctx, "https://example.com/original", tt.opts)
+					if err != nil {
+						t.Fatalf("CreateShortURL: %v", err)
+					}
+					if tt.setup != nil {
+						tt.setup(t, svc, created.ShortCode)
+					}
+					before, err := svc.GetURLMetadata(ctx, created.ShortCode)
+					if err != nil {
+						t.Fatalf("GetURLMetadata: %v", err)
+					}
+
+					redirect, err := svc.ResolveRedirect(ctx, created.ShortCode, testVisit(tt.visit))
+					if tt.wantErr != nil {
+						if !errors.Is(err, tt.wantErr) {
+							t.Fatalf("got err %v, want %v", err, tt.wantErr)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatalf("ResolveRedirect: %v", err)
+					}
+
+					if redirect.Location != tt.wantLocation {
+						t.Errorf("location = %q, want %q", redirect.Location, tt.wantLocation)
+					}
+					if redirect.Fallback != tt.wantFallback {
+						t.Errorf("fallback = %v, want %v", redirect.Fallback, tt.wantFallback)
+					}
+					if redirect.Preview != tt.wantPreview {
+						t.Errorf("preview = %v, want %v", redirect.Preview, tt.wantPreview)
+					}
+					if redirect.TargetRule != tt.wantRule {
+						t.Errorf("target rule = %q, want %q", redirect.TargetRule, tt.wantRule)
+					}
+					if tt.wantVariant != "" && redirect.Variant != tt.wantVariant {
+						t.Errorf("variant = %q, want %q", redirect.Variant, tt.wantVariant)
+					}
+
+					after, err := svc.GetURLMetadata(ctx, created.ShortCode)
+					if err != nil {
+						t.Fatalf("GetURLMetadata: %v", err)
+					}
+					counted := after.UsedClicks > before.UsedClicks
+					if before.MaxClicks != nil && counted != tt.wantCounted {
+						t.Errorf("click counted = %v, want %v", counted, tt.wantCounted)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestResolveRedirectRateLimitsPasswords(t *testing.T) {
+	ctx := context.Background()
+	backend := testBackends(t)[0]
+	svc := newTestURLService(t, backend)
+
+	created, _, err := svc.CreateShortURL(ctx, "https://example.com/private", CreateURLOptions{Password: "secret"})
+	if err != nil {
+		t.Fatalf("CreateShortURL: %v", err)
+	}
+
+	for i := range svc.config.PasswordMaxAttempts {
+		if _, err := svc.ResolveRedirect(ctx, created.ShortCode, testVisit(Visit{Password: "guess"})); !errors.Is(err, domain.ErrIncorrectPassword) {
+			t.Fatalf("attempt %d: got err %v, want ErrIncorrectPassword", i+1, err)
+		}
+	}
+
+	if _, err := svc.ResolveRedirect(ctx, created.ShortCode, testVisit(Visit{Password: "secret"})); !errors.Is(err, domain.ErrTooManyPasswordAttempts) {
+		t.Errorf("correct password after lockout: got err %v, want ErrTooManyPasswordAttempts", err)
+	}
+
+	other := testVisit(Visit{Password: "secret"})
+	other.Click.IPAddress = "192.0.2.99"
+	if _, err := svc.ResolveRedirect(ctx, created.ShortCode, other); err != nil {
+		t.Errorf("another client: %v", err)
+	}
+}
+
+func TestResolveRedirectRateLimitsParallelPasswords(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestURLService(t, testBackends(t)[0])
+
+	created, _, err := svc.CreateShortURL(ctx, "https://example.com/private", CreateURLOptions{Password: "secret"})
+	if err != nil {
+		t.Fatalf("CreateShortURL: %v", err)
+	}
+
+	guesses := 4 * svc.config.PasswordMaxAttempts
+	errs := make(chan error, guesses)
+	for range guesses {
+		go func() {
+			_, err := svc.ResolveRedirect(ctx, created.ShortCode, testVisit(Visit{Password: "guess"}))
+			errs <- err
+		}()
+	}
+
+	var compared int
+	for range guesses {
+		switch err := <-errs; {
+		case errors.Is(err, domain.ErrIncorrectPassword):
+			compared++
+		case !errors.Is(err, domain.ErrTooManyPasswordAttempts):
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if compared != svc.config.PasswordMaxAttempts {
+		t.Errorf("%d parallel guesses were checked, want %d", compared, svc.config.PasswordMaxAttempts)
+	}
+}
+
+func TestBuildLocation(t *testing.T) {
+	svc := newTestURLService(t, testBackends(t)[0])
+
+	tests := []struct {
+		name        string
+		destination string
+		url         domain.URL
+		path        string
+		query       url.Values
+		want        string
+		wantErr     error
+	}{
+		{
+			name:        "no passthrough",
+			destination: "https://example.com/docs",
+			query:       url.Values{"q": {"1"}},
+			want:        "https://example.com/docs",
+		},
+		{
+			name:        "path",
+			destination: "https://example.com/docs",
+			url:         domain.URL{PathPassthrough: true},
+			path:        "guide/intro",
+			want:        "https://example.com/docs/guide/intro",
+		},
+		{
+			name:        "escaped path segment",
+			destination: "https://example.com/docs",
+			url:         domain.URL{PathPassthrough: true},
+			path:        "a%20b",
+			want:        "https://example.com/docs/a%20b",
+		},
+		{name: "parent segment", destination: "https://example.com/docs", path: "../admin", wantErr: domain.ErrURLNotFound},
+		{name: "nested parent segment", destination: "https://example.com/docs", path: "guide/../../admin", wantErr: domain.ErrURLNotFound},
+		{name: "current segment", destination: "https://example.com/docs", path: "./admin", wantErr: domain.ErrURLNotFound},
+		{name: "escaped parent segment", destination: "https://example.com/docs", path: "%2e%2e/admin", wantErr: domain.ErrURLNotFound},
+		{name: "mixed case escaped parent segment", destination: "https://example.com/docs", path: ".%2E/admin", wantErr: domain.ErrURLNotFound},
+		{name: "escaped current segment", destination: "https://example.com/docs", path: "%2E", wantErr: domain.ErrURLNotFound},
+		{name: "invalid escape", destination: "https://example.com/docs", path: "%zz", wantErr: domain.ErrURLNotFound},
+		{
+			name:        "dots inside a segment",
+			destination: "https://example.com/docs",
+			path:        "v1..2/file.tar.gz",
+			want:        "https://example.com/docs/v1..2/file.tar.gz",
+		},
+		{
+			name:        "destination query wins",
+			destination: "https://example.com/?a=1",
+			url:         domain.URL{QueryPassthrough: true},
+			query:       url.Values{"a": {"2"}, "b": {"3"}},
+			want:        "https://example.com/?a=1&b=3",
+		},
+		{
+			name:        "request query wins",
+			destination: "https://example.com/?a=1",
+			url:         domain.URL{QueryPassthrough: true, QueryPrecedence: domain.QueryPrecedenceRequest},
+			query:       url.Values{"a": {"2"}},
+			want:        "https://example.com/?a=2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.buildLocation(&tt.url, tt.destination, Visit{Path: tt.path, Query: tt.query})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %q, err %v, want err %v", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildLocation: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// testVisit fills in the click of visit with a fixed client.
+func testVisit(visit Visit) Visit {
+	if visit.Click == nil {
+		visit.Click = &domain.Click{}
+	}
+	if visit.Click.IPAddress == "" {
+		visit.Click.IPAddress = "192.0.2.1"
+	}
+	return visit
+}
+
+func disableURL(t *testing.T, svc *URLService, code string) {
+	t.Helper()
+
+	if _, err := svc.DisableURL(context.Background(), code, "tester"); err != nil {
+		t.Fatalf("DisableURL: %v", err)
+	}
+}
+
+// expireURL moves the expiry of a link into the past, which the service itself never allows.
+func expireURL(t *testing.T, svc *URLService, code string, at time.Time) {
+	t.Helper()
+
+	ctx := context.Background()
+	url, err := svc.repo.GetByShortCode(ctx, code)
+	if err != nil {
+		t.Fatalf("GetByShortCode: %v", err)
+	}
+	url.ExpiresAt = &at
+	if err := svc.repo.UpdateAttributes(ctx, url, domain.Change{Action: domain.RevisionUpdate}); err != nil {
+		t.Fatalf("UpdateAttributes: %v", err)
+	}
+}
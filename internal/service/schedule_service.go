@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/edson-mazvila/url-shortener/internal/repository"
+)
+
+const (
+	// maxSchedulesPerURL bounds the pending changes of a single link.
+	maxSchedulesPerURL = 50
+	// scheduleBatchSize is the number of due changes applied per scheduler run.
+	scheduleBatchSize = 100
+	// schedulerActor is recorded in the history of URLs changed by the scheduler.
+	schedulerActor = "scheduler"
+)
+
+// errURLSoftDeleted reports that a due change belongs to a soft-deleted URL, so it is kept in case the
+// URL is restored.
+var errURLSoftDeleted = errors.New("url is soft-deleted")
+
+// ScheduleService manages scheduled destination changes and applies them once they are due.
+type ScheduleService struct {
+	store  repository.ScheduleStore
+	urls   *URLService
+	logger *slog.Logger
+}
+
+// NewScheduleService creates a new schedule service.
+func NewScheduleService(store repository.ScheduleStore, urls *URLService, logger *slog.Logger) *ScheduleService {
+	return &ScheduleService{
+		store:  store,
+		urls:   urls,
+		logger: logger,
+	}
+}
+
+// ScheduleChange schedules the URL identified by shortCode to switch to originalURL at applyAt.
+func (s *ScheduleService) ScheduleChange(ctx context.Context, shortCode, originalURL string, applyAt time.Time, actor string) (*domain.ScheduledChange, error) {
+	urlEntity, err := s.urls.repo.GetByShortCode(ctx, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.urls.validateURL(originalURL); err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+
+	now := time.Now()
+	if !applyAt.After(now) {
+		return nil, fmt.Errorf("apply_at must be in the future: %w", domain.ErrInvalidSchedule)
+	}
+
+	pending, err := s.store.ListSchedules(ctx, urlEntity.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled changes: %w", err)
+	}
+	if len(pending) >= maxSchedulesPerURL {
+		return nil, fmt.Errorf("at most %d changes may be pending: %w", maxSchedulesPerURL, domain.ErrInvalidSchedule)
+	}
+
+	schedule := &domain.ScheduledChange{
+		URLID:       urlEntity.ID,
+		ShortCode:   urlEntity.ShortCode,
+		OriginalURL: originalURL,
+		ApplyAt:     applyAt,
+		CreatedBy:   actor,
+		CreatedAt:   now,
+	}
+
+	if err := s.store.CreateSchedule(ctx, schedule); err != nil {
+		return nil, fmt.Errorf("failed to schedule change: %w", err)
+	}
+
+	s.logger.Info("url change scheduled",
+		slog.String("short_code", shortCode),
+		slog.Int64("id", schedule.ID),
+		slog.Time("apply_at", applyAt),
+	)
+
+	return schedule, nil
+}
+
+// ListChanges returns the pending changes of the URL identified by shortCode in the order they apply.
+func (s *ScheduleService) ListChanges(ctx context.Context, shortCode string) ([]*domain.ScheduledChange, error) {
+	urlEntity, err := s.urls.repo.GetByShortCode(ctx, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules, err := s.store.ListSchedules(ctx, urlEntity.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled changes: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// CancelChange deletes the pending change id of the URL identified by shortCode.
+func (s *ScheduleService) CancelChange(ctx context.Context, shortCode string, id int64) error {
+	urlEntity, err := s.urls.repo.GetByShortCode(ctx, shortCode)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.DeleteSchedule(ctx, urlEntity.ID, id); err != nil {
+		if errors.Is(err, domain.ErrScheduleNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to cancel scheduled change: %w", err)
+	}
+
+	s.logger.Info("scheduled url change cancelled",
+		slog.String("short_code", shortCode),
+		slog.Int64("id", id),
+	)
+
+	return nil
+}
+
+// ApplyDue applies the changes that are due and returns how many were applied. Changes that can
+// never apply, such as those of purged URLs, are dropped; changes of soft-deleted URLs wait for the
+// URL to be restored, and changes that failed otherwise are retried on the next run.
+func (s *ScheduleService) ApplyDue(ctx context.Context) (int, error) {
+	due, err := s.store.ListDueSchedules(ctx, time.Now(), scheduleBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list due scheduled changes: %w", err)
+	}
+
+	var applied int
+	for _, schedule := range due {
+		err := s.apply(ctx, schedule)
+		switch {
+		case err == nil:
+			applied++
+		case errors.Is(err, errURLSoftDeleted):
+			continue
+		case errors.Is(err, domain.ErrURLNotFound) || errors.Is(err, domain.ErrInvalidURL):
+			s.logger.Warn("dropping scheduled url change",
+				slog.String("short_code", schedule.ShortCode),
+				slog.Int64("id", schedule.ID),
+				slog.String("error", err.Error()),
+			)
+		default:
+			s.logger.Error("failed to apply scheduled url change",
+				slog.String("short_code", schedule.ShortCode),
+				slog.Int64("id", schedule.ID),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+
+		if err := s.store.DeleteSchedule(ctx, schedule.URLID, schedule.ID); err != nil && !errors.Is(err, domain.ErrScheduleNotFound) {
+			return applied, fmt.Errorf("failed to delete applied scheduled change: %w", err)
+		}
+	}
+
+	return applied, nil
+}
+
+// apply switches the destination of the URL a change was scheduled for.
+func (s *ScheduleService) apply(ctx context.Context, schedule *domain.ScheduledChange) error {
+	urlEntity, err := s.urls.repo.GetByShortCode(ctx, schedule.ShortCode)
+	if errors.Is(err, domain.ErrURLNotFound) {
+		deleted, lookupErr := s.urls.repo.GetDeleted(ctx, schedule.ShortCode)
+		switch {
+		case lookupErr == nil && deleted.ID == schedule.URLID:
+			return errURLSoftDeleted
+		case lookupErr != nil && !errors.Is(lookupErr, domain.ErrURLNotFound):
+			return lookupErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	// The short code may have been freed and reused by another link since the change was scheduled.
+	if urlEntity.ID != schedule.URLID {
+		return domain.ErrURLNotFound
+	}
+
+	_, err = s.urls.UpdateURL(ctx, schedule.ShortCode, urlEntity.Version, URLUpdate{
+		OriginalURL: &schedule.OriginalURL,
+		Actor:       schedulerActor,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("scheduled url change applied",
+		slog.String("short_code", schedule.ShortCode),
+		slog.Int64("id", schedule.ID),
+		slog.String("original_url", schedule.OriginalURL),
+	)
+
+	return nil
+}
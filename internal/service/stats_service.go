@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/edson-mazvila/url-shortener/internal/repository"
+	"github.com/edson-mazvila/url-shortener/internal/useragent"
+)
+
+const (
+	// statsTopN is the number of referrers, user agents and browsers returned.
+	statsTopN = 10
+	// statsUserAgentSample is the number of distinct user agents browser families are derived from.
+	statsUserAgentSample = 500
+	// statsMaxBuckets bounds the length of a returned time series.
+	statsMaxBuckets = 2000
+)
+
+// StatsService provides click analytics for shortened URLs.
+type StatsService struct {
+	urls   repository.URLStore
+	clicks repository.ClickStore
+	logger *slog.Logger
+}
+
+// NewStatsService creates a new stats service.
+func NewStatsService(urls repository.URLStore, clicks repository.ClickStore, logger *slog.Logger) *StatsService {
+	return &StatsService{
+		urls:   urls,
+		clicks: clicks,
+		logger: logger,
+	}
+}
+
+// GetClickStats returns click counts for a short code bucketed by interval within [from, to).
+// Zero values for from and to default to a range ending now that suits the interval.
+func (s *StatsService) GetClickStats(ctx context.Context, shortCode string, from, to time.Time, interval domain.StatsInterval) (*domain.ClickStats, error) {
+	if interval == "" {
+		interval = domain.IntervalDay
+	}
+	if !interval.Valid() {
+		return nil, fmt.Errorf("unsupported interval %q: %w", interval, domain.ErrInvalidStatsQuery)
+	}
+
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = defaultStatsFrom(to, interval)
+	}
+	from, to = from.UTC(), to.UTC()
+
+	if !from.Before(to) {
+		return nil, fmt.Errorf("from must be before to: %w", domain.ErrInvalidStatsQuery)
+	}
+
+	buckets := 0
+	for start := interval.Truncate(from); start.Before(to); start = interval.Next(start) {
+		if buckets++; buckets > statsMaxBuckets {
+			return nil, fmt.Errorf("range exceeds %d %s buckets: %w", statsMaxBuckets, interval, domain.ErrInvalidStatsQuery)
+		}
+	}
+
+	urlEntity, err := s.urls.GetByShortCode(ctx, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.clicks.ClickStats(ctx, domain.ClickStatsQuery{
+		URLID:    urlEntity.ID,
+		From:     from,
+		To:       to,
+		Interval: interval,
+		TopN:     statsUserAgentSample,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get click stats: %w", err)
+	}
+
+	stats.ShortCode = shortCode
+	stats.Series = fillSeries(stats.Series, from, to, interval)
+	stats.TopBrowsers = browserCounts(stats.TopUserAgents)
+	stats.TopReferrers = truncateEntries(stats.TopReferrers, statsTopN)
+	stats.TopUserAgents = truncateEntries(stats.TopUserAgents, statsTopN)
+	stats.TopTargetingRules = truncateEntries(stats.TopTargetingRules, statsTopN)
+	stats.TopCountries = truncateEntries(stats.TopCountries, statsTopN)
+
+	return stats, nil
+}
+
+func defaultStatsFrom(to time.Time, interval domain.StatsInterval) time.Time {
+	switch interval {
+	case domain.IntervalHour:
+		return to.Add(-24 * time.Hour)
+	case domain.IntervalWeek:
+		return to.AddDate(0, 0, -7*12)
+	default:
+		return to.AddDate(0, 0, -30)
+	}
+}
+
+// fillSeries returns one bucket per interval in the range, inserting zero buckets where no clicks occurred.
+func fillSeries(series []domain.ClickBucket, from, to time.Time, interval domain.StatsInterval) []domain.ClickBucket {
+	byStart := make(map[time.Time]domain.ClickBucket, len(series))
+	for _, bucket := range series {
+		byStart[bucket.Start.UTC()] = bucket
+	}
+
+	filled := make([]domain.ClickBucket, 0)
+	for start := interval.Truncate(from); start.Before(to); start = interval.Next(start) {
+		bucket, ok := byStart[start]
+		if !ok {
+			bucket = domain.ClickBucket{Start: start}
+		}
+		filled = append(filled, bucket)
+	}
+
+	return filled
+}
+
+func browserCounts(userAgents []domain.CountEntry) []domain.CountEntry {
+	counts := make(map[string]int64)
+	for _, entry := range userAgents {
+		counts[useragent.Parse(entry.Value).Browser] += entry.Count
+	}
+
+	entries := make([]domain.CountEntry, 0, len(counts))
+	for browser, count := range counts {
+		entries = append(entries, domain.CountEntry{Value: browser, Count: count})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count == entries[j].Count {
+			return entries[i].Value < entries[j].Value
+		}
+		return entries[i].Count > entries[j].Count
+	})
+
+	return truncateEntries(entries, statsTopN)
+}
+
+func truncateEntries(entries []domain.CountEntry, limit int) []domain.CountEntry {
+	if entries == nil {
+		return []domain.CountEntry{}
+	}
+	if len(entries) > limit {
+		return entries[:limit]
+	}
+	return entries
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/edson-mazvila/url-shortener/internal/useragent"
+)
+
+const (
+	// maxTargetingRules bounds the rules evaluated on every redirect of a link.
+	maxTargetingRules = 20
+	// maxTargetingRuleNameLength matches the clicks.target_rule column.
+	maxTargetingRuleNameLength = 64
+)
+
+var (
+	targetingOSes     = []string{useragent.OSiOS, useragent.OSAndroid, useragent.OSWindows, useragent.OSMacOS, useragent.OSLinux, useragent.OSChromeOS, useragent.OSOther}
+	targetingDevices  = []string{useragent.DeviceDesktop, useragent.DeviceMobile, useragent.DeviceTablet, useragent.DeviceBot}
+	targetingBrowsers = []string{useragent.BrowserChrome, useragent.BrowserSafari, useragent.BrowserFirefox, useragent.BrowserEdge, useragent.BrowserOpera, useragent.BrowserSamsung, useragent.BrowserIE, useragent.BrowserOther}
+
+	countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+	regionCodePattern  = regexp.MustCompile(`^[A-Z]{2}-[A-Z0-9]{1,3}$`)
+)
+
+// validateTargetingRules checks rules and names unnamed rules after their position, starting at "rule-1".
+func (s *URLService) validateTargetingRules(rules []domain.TargetingRule) ([]domain.TargetingRule, error) {
+	if len(rules) > maxTargetingRules {
+		return nil, fmt.Errorf("at most %d rules are allowed: %w", maxTargetingRules, domain.ErrInvalidTargetingRule)
+	}
+
+	validated := make([]domain.TargetingRule, len(rules))
+	names := make(map[string]bool, len(rules))
+	var err error
+
+	for i, rule := range rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		if len(rule.Name) > maxTargetingRuleNameLength {
+			return nil, fmt.Errorf("rule name %q exceeds %d characters: %w", rule.Name, maxTargetingRuleNameLength, domain.ErrInvalidTargetingRule)
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("duplicate rule name %q: %w", rule.Name, domain.ErrInvalidTargetingRule)
+		}
+		names[rule.Name] = true
+
+		if rule.OS == "" && rule.Device == "" && rule.Browser == "" && len(rule.Countries) == 0 && len(rule.Regions) == 0 {
+			return nil, fmt.Errorf("rule %q has no conditions: %w", rule.Name, domain.ErrInvalidTargetingRule)
+		}
+		if rule.OS != "" && !slices.Contains(targetingOSes, rule.OS) {
+			return nil, fmt.Errorf("rule %q has unknown os %q: %w", rule.Name, rule.OS, domain.ErrInvalidTargetingRule)
+		}
+		if rule.Device != "" && !slices.Contains(targetingDevices, rule.Device) {
+			return nil, fmt.Errorf("rule %q has unknown device %q: %w", rule.Name, rule.Device, domain.ErrInvalidTargetingRule)
+		}
+		if rule.Browser != "" && !slices.Contains(targetingBrowsers, rule.Browser) {
+			return nil, fmt.Errorf("rule %q has unknown browser %q: %w", rule.Name, rule.Browser, domain.ErrInvalidTargetingRule)
+		}
+
+		if rule.Countries, err = normalizeGeoCodes(rule.Countries, countryCodePattern); err != nil {
+			return nil, fmt.Errorf("rule %q has an invalid country: %w", rule.Name, err)
+		}
+		if rule.Regions, err = normalizeGeoCodes(rule.Regions, regionCodePattern); err != nil {
+			return nil, fmt.Errorf("rule %q has an invalid region: %w", rule.Name, err)
+		}
+
+		if err := s.validateURL(rule.URL); err != nil {
+			return nil, fmt.Errorf("rule %q has an invalid url: %w", rule.Name, domain.ErrInvalidTargetingRule)
+		}
+
+		validated[i] = rule
+	}
+
+	return validated, nil
+}
+
+// normalizeGeoCodes upper-cases ISO 3166 codes and checks them against pattern.
+func normalizeGeoCodes(codes []string, pattern *regexp.Regexp) ([]string, error) {
+	normalized := make([]string, 0, len(codes))
+	for _, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if !pattern.MatchString(code) {
+			return nil, fmt.Errorf("%q is not an ISO 3166 code: %w", code, domain.ErrInvalidTargetingRule)
+		}
+		normalized = append(normalized, code)
+	}
+
+	if len(normalized) == 0 {
+		return nil, nil
+	}
+
+	return normalized, nil
+}
+
+// matchTargetingRule returns the first rule matching visitor, or nil if none does.
+func matchTargetingRule(rules []domain.TargetingRule, visitor domain.Visitor) *domain.TargetingRule {
+	for i := range rules {
+		if rules[i].Matches(visitor) {
+			return &rules[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+)
+
+// URLHistory is the change log of a URL, newest first.
+type URLHistory struct {
+	ShortCode      string          `json:"short_code"`
+	CurrentVersion int64           `json:"current_version"`
+	Revisions      []*HistoryEntry `json:"revisions"`
+}
+
+// HistoryEntry is one revision together with the values it replaced.
+type HistoryEntry struct {
+	*domain.URLRevision
+	Previous *RevisionValues `json:"previous,omitempty"`
+}
+
+// RevisionValues holds the destination and expiry of a URL at one version.
+type RevisionValues struct {
+	OriginalURL string     `json:"original_url"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// GetURLHistory returns every recorded change to a URL's destination and expiry.
+func (s *URLService) GetURLHistory(ctx context.Context, shortCode string) (*URLHistory, error) {
+	urlEntity, err := s.repo.GetByShortCode(ctx, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.repo.ListRevisions(ctx, urlEntity.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list url history: %w", err)
+	}
+
+	history := &URLHistory{
+		ShortCode:      urlEntity.ShortCode,
+		CurrentVersion: urlEntity.Version,
+		Revisions:      make([]*HistoryEntry, len(revisions)),
+	}
+
+	for i, revision := range revisions {
+		entry := &HistoryEntry{URLRevision: revision}
+		if i+1 < len(revisions) {
+			previous := revisions[i+1]
+			entry.Previous = &RevisionValues{
+				OriginalURL: previous.OriginalURL,
+				ExpiresAt:   previous.ExpiresAt,
+			}
+		}
+		history.Revisions[i] = entry
+	}
+
+	return history, nil
+}
+
+// RollbackURL restores the destination and expiry a URL had at targetVersion. A non-zero version makes
+// the rollback conditional on the URL not having been modified since that version.
+func (s *URLService) RollbackURL(ctx context.Context, shortCode string, version, targetVersion int64, actor string) (*domain.URL, error) {
+	urlEntity, err := s.repo.GetByShortCode(ctx, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != 0 && urlEntity.Version != version {
+		return nil, domain.ErrVersionMismatch
+	}
+
+	revision, err := s.repo.GetRevision(ctx, urlEntity.ID, targetVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if revision.ExpiresAt != nil && !revision.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("version %d expired at %s: %w", targetVersion, revision.ExpiresAt.Format(time.RFC3339), domain.ErrInvalidExpiry)
+	}
+
+	normalizedURL, err := normalizeURL(revision.OriginalURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+
+	urlEntity.OriginalURL = revision.OriginalURL
+	urlEntity.NormalizedURL = normalizedURL
+	urlEntity.ExpiresAt = revision.ExpiresAt
+
+	if err := validateActivation(urlEntity); err != nil {
+		return nil, err
+	}
+
+	// History records the composed destination, so its UTM parameters are split off again for editing.
+	urlEntity.BaseURL, urlEntity.UTM = "", nil
+	if baseURL, params := splitUTM(revision.OriginalURL); !params.IsZero() {
+		urlEntity.BaseURL, urlEntity.UTM = baseURL, &params
+	}
+
+	change := domain.Change{
+		Action:          domain.RevisionRollback,
+		Actor:           actor,
+		RestoredVersion: targetVersion,
+	}
+	if err := s.saveAttributes(ctx, urlEntity, change); err != nil {
+		return nil, err
+	}
+
+	return urlEntity, nil
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+)
+
+// DisableURL stops the URL identified by shortCode from redirecting until it is enabled again.
+// Disabling a disabled URL has no effect.
+func (s *URLService) DisableURL(ctx context.Context, shortCode, actor string) (*domain.URL, error) {
+	urlEntity, err := s.repo.GetByShortCode(ctx, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if urlEntity.IsDisabled() {
+		return urlEntity, nil
+	}
+
+	now := time.Now()
+	urlEntity.DisabledAt = &now
+
+	change := domain.Change{Action: domain.RevisionDisable, Actor: actor}
+	if err := s.saveAttributes(ctx, urlEntity, change); err != nil {
+		return nil, err
+	}
+
+	return urlEntity, nil
+}
+
+// EnableURL lets a disabled URL redirect again. Enabling an enabled URL has no effect.
+func (s *URLService) EnableURL(ctx context.Context, shortCode, actor string) (*domain.URL, error) {
+	urlEntity, err := s.repo.GetByShortCode(ctx, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if !urlEntity.IsDisabled() {
+		return urlEntity, nil
+	}
+
+	urlEntity.DisabledAt = nil
+
+	change := domain.Change{Action: domain.RevisionEnable, Actor: actor}
+	if err := s.saveAttributes(ctx, urlEntity, change); err != nil {
+		return nil, err
+	}
+
+	return urlEntity, nil
+}
+
+// DeleteURL soft-deletes the URL identified by shortCode. It stops resolving at once, but its short
+// code stays reserved, and the URL can be restored, until PurgeDeleted removes it after the configured
+// quarantine.
+func (s *URLService) DeleteURL(ctx context.Context, shortCode, actor string) error {
+	urlEntity, err := s.repo.GetByShortCode(ctx, shortCode)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	urlEntity.DeletedAt = &now
+
+	change := domain.Change{Action: domain.RevisionDelete, Actor: actor}
+	if err := s.saveAttributes(ctx, urlEntity, change); err != nil {
+		return fmt.Errorf("failed to delete url: %w", err)
+	}
+
+	s.logger.Info("url deleted", slog.String("short_code", shortCode))
+
+	return nil
+}
+
+// RestoreURL undoes the soft deletion of the URL identified by shortCode. A restored URL keeps its
+// disabled state.
+func (s *URLService) RestoreURL(ctx context.Context, shortCode, actor string) (*domain.URL, error) {
+	urlEntity, err := s.repo.GetDeleted(ctx, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	urlEntity.DeletedAt = nil
+
+	change := domain.Change{Action: domain.RevisionRestore, Actor: actor}
+	if err := s.saveAttributes(ctx, urlEntity, change); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("url restored", slog.String("short_code", shortCode))
+
+	return urlEntity, nil
+}
+
+// PurgeDeleted permanently removes URLs deleted longer ago than the configured quarantine, freeing
+// their short codes.
+func (s *URLService) PurgeDeleted(ctx context.Context) (int64, error) {
+	count, err := s.repo.PurgeDeleted(ctx, time.Now().Add(-s.config.DeleteQuarantine))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted urls: %w", err)
+	}
+
+	return count, nil
+}
@@ -3,7 +3,7 @@ package service
 import (
 	"context"
 	"crypto/rand"
-	"encoding/base64"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/url"
@@ -12,125 +12,427 @@ import (
 
 	"github.com/edson-mazvila/url-shortener/internal/config"
 	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/edson-mazvila/url-shortener/internal/geoip"
 	"github.com/edson-mazvila/url-shortener/internal/repository"
 )
 
 // URLService provides business logic for URL operations.
 type URLService struct {
-	repo   *repository.URLRepository
-	config *config.URLConfig
-	logger *slog.Logger
+	repo       repository.URLStore
+	templates  repository.UTMTemplateStore
+	codes      CodeGenerator
+	collisions collisionTracker
+	clicks     *ClickAggregator
+	recorder   *ClickRecorder
+	geo        *geoip.Resolver
+	attempts   *attemptLimiter
+	config     *config.URLConfig
+	logger     *slog.Logger
 }
 
 // NewURLService creates a new URL service.
-func NewURLService(repo *repository.URLRepository, cfg *config.URLConfig, logger *slog.Logger) *URLService {
+func NewURLService(repo repository.URLStore, templates repository.UTMTemplateStore, codes CodeGenerator, clicks *ClickAggregator, recorder *ClickRecorder, geo *geoip.Resolver, cfg *config.URLConfig, logger *slog.Logger) *URLService {
 	return &URLService{
-		repo:   repo,
-		config: cfg,
-		logger: logger,
+		repo:      repo,
+		templates: templates,
+		codes:     codes,
+		clicks:    clicks,
+		recorder:  recorder,
+		geo:       geo,
+		attempts:  newAttemptLimiter(cfg.PasswordMaxAttempts, cfg.PasswordLockout),
+		config:    cfg,
+		logger:    logger,
 	}
 }
 
-// CreateShortURL creates a new shortened URL.
-func (s *URLService) CreateShortURL(ctx context.Context, originalURL string, customCode string, ttl time.Duration) (*domain.URL, error) {
+// CreateURLOptions holds the optional settings of a new short URL.
+type CreateURLOptions struct {
+	CustomCode string
+	TTL        time.Duration
+	// Dedupe returns an existing unexpired link for the same normalized destination instead of creating one.
+	// It is ignored when CustomCode, Password, MaxClicks or ActivatesAt is set, and never returns a
+	// password-protected, click-limited, inactive or disabled link.
+	Dedupe bool
+	// RedirectType is the redirect status code; 0 uses the configured default.
+	RedirectType int
+	// QueryPassthrough, QueryPrecedence and PathPassthrough control how visits are forwarded.
+	QueryPassthrough bool
+	QueryPrecedence  string
+	PathPassthrough  bool
+	// UTMTemplate names a template whose parameters are applied to the destination; UTM overrides them.
+	UTMTemplate string
+	UTM         domain.UTMParams
+	// TargetingRules send matching user agents to other destinations, in order.
+	TargetingRules []domain.TargetingRule
+	// Variants split the visits no targeting rule matches across weighted destinations; StickyVariants
+	// keeps returning visitors on the variant they saw first.
+	Variants       []domain.Variant
+	StickyVariants bool
+	// Password protects the link; visitors must enter it before being redirected.
+	Password string
+	// MaxClicks is the number of redirects after which the link stops working; 0 means unlimited.
+	MaxClicks int64
+	// ActivatesAt is the time before which the link does not redirect; nil makes it live immediately.
+	ActivatesAt *time.Time
+	// AlwaysPreview shows visitors a preview page instead of redirecting them.
+	AlwaysPreview bool
+	// FallbackURL is where visitors are sent once the link is unavailable; empty shows an error instead.
+	FallbackURL string
+	// Actor identifies who created the link in its history.
+	Actor string
+}
+
+// CreateShortURL creates a new shortened URL. It reports false when an existing link was returned instead.
+func (s *URLService) CreateShortURL(ctx context.Context, originalURL string, opts CreateURLOptions) (*domain.URL, bool, error) {
 	if err := s.validateURL(originalURL); err != nil {
-		return nil, fmt.Errorf("invalid url: %w", err)
+		return nil, false, fmt.Errorf("invalid url: %w", err)
 	}
 
-	var shortCode string
-	var err error
+	if opts.CustomCode != "" {
+		if err := s.validateShortCode(opts.CustomCode); err != nil {
+			return nil, false, err
+		}
+	}
 
-	if customCode != "" {
-		if err := s.validateShortCode(customCode); err != nil {
-			return nil, err
+	if opts.RedirectType != 0 && !domain.IsValidRedirectType(opts.RedirectType) {
+		return nil, false, domain.ErrInvalidRedirectType
+	}
+
+	if opts.QueryPrecedence != "" && !domain.IsValidQueryPrecedence(opts.QueryPrecedence) {
+		return nil, false, domain.ErrInvalidQueryPrecedence
+	}
+
+	params, err := s.resolveUTM(ctx, opts.UTMTemplate, opts.UTM)
+	if err != nil {
+		return nil, false, err
+	}
+
+	rules, err := s.validateTargetingRules(opts.TargetingRules)
+	if err != nil {
+		return nil, false, err
+	}
+
+	variants, err := s.validateVariants(opts.Variants)
+	if err != nil {
+		return nil, false, err
+	}
+
+	urlEntity := &domain.URL{
+		ShortCode:        opts.CustomCode,
+		RedirectType:     opts.RedirectType,
+		QueryPassthrough: opts.QueryPassthrough,
+		QueryPrecedence:  opts.QueryPrecedence,
+		PathPassthrough:  opts.PathPassthrough,
+		TargetingRules:   rules,
+		Variants:         variants,
+		StickyVariants:   opts.StickyVariants,
+		AlwaysPreview:    opts.AlwaysPreview,
+	}
+
+	if opts.FallbackURL != "" {
+		if err := s.validateURL(opts.FallbackURL); err != nil {
+			return nil, false, fmt.Errorf("invalid fallback url: %w", err)
 		}
-		shortCode = customCode
-	} else {
-		shortCode, err = s.generateShortCode(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate short code: %w", err)
+		urlEntity.FallbackURL = opts.FallbackURL
+	}
+
+	if opts.Password != "" {
+		if urlEntity.PasswordHash, err = hashPassword(opts.Password); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if opts.MaxClicks < 0 {
+		return nil, false, domain.ErrInvalidClickLimit
+	}
+	if opts.MaxClicks > 0 {
+		urlEntity.MaxClicks = &opts.MaxClicks
+	}
+
+	if err := setDestination(urlEntity, originalURL, params); err != nil {
+		return nil, false, err
+	}
+
+	if opts.Dedupe && opts.CustomCode == "" && opts.Password == "" && opts.MaxClicks == 0 && opts.ActivatesAt == nil {
+		existing, err := s.repo.FindByNormalizedURL(ctx, urlEntity.NormalizedURL)
+		if err == nil && !existing.IsPasswordProtected() && existing.MaxClicks == nil && existing.IsActive() && !existing.IsDisabled() {
+			s.logger.Info("returning existing short url for duplicate destination",
+				slog.String("short_code", existing.ShortCode),
+				slog.String("original_url", urlEntity.OriginalURL),
+			)
+			return existing, false, nil
+		}
+		if err != nil && !errors.Is(err, domain.ErrURLNotFound) {
+			return nil, false, fmt.Errorf("failed to find duplicate url: %w", err)
 		}
 	}
 
 	now := time.Now()
 	var expiresAt *time.Time
 
-	if ttl > 0 {
-		expiry := now.Add(ttl)
+	if opts.TTL > 0 {
+		expiry := now.Add(opts.TTL)
 		expiresAt = &expiry
 	} else if s.config.DefaultTTL > 0 {
 		expiry := now.Add(s.config.DefaultTTL)
 		expiresAt = &expiry
 	}
 
-	urlEntity := &domain.URL{
-		ShortCode:   shortCode,
-		OriginalURL: originalURL,
-		CreatedAt:   now,
-		ExpiresAt:   expiresAt,
-		AccessCount: 0,
+	urlEntity.CreatedAt = now
+	urlEntity.ExpiresAt = expiresAt
+	urlEntity.ActivatesAt = opts.ActivatesAt
+
+	if err := validateActivation(urlEntity); err != nil {
+		return nil, false, err
 	}
 
-	if err := s.repo.Create(ctx, urlEntity); err != nil {
-		return nil, fmt.Errorf("failed to create url: %w", err)
+	change := domain.Change{Action: domain.RevisionCreate, Actor: opts.Actor}
+	if err := s.insert(ctx, urlEntity, change); err != nil {
+		return nil, false, fmt.Errorf("failed to create url: %w", err)
 	}
 
 	s.logger.Info("short url created",
-		slog.String("short_code", shortCode),
-		slog.String("original_url", originalURL),
+		slog.String("short_code", urlEntity.ShortCode),
+		slog.String("original_url", urlEntity.OriginalURL),
 		slog.Any("expires_at", expiresAt),
+		slog.Any("activates_at", urlEntity.ActivatesAt),
 	)
 
-	return urlEntity, nil
+	return urlEntity, true, nil
+}
+
+// URLUpdate describes the changes applied by UpdateURL. Zero fields leave the attribute unchanged.
+type URLUpdate struct {
+	OriginalURL *string
+	// SetExpiry replaces the expiry with ExpiresAt; a nil ExpiresAt removes it.
+	SetExpiry bool
+	ExpiresAt *time.Time
+	// SetActivation replaces the activation time with ActivatesAt; a nil ActivatesAt makes the link live.
+	SetActivation bool
+	ActivatesAt   *time.Time
+	// ExtendBy pushes the current expiry, or now if it has already passed, further into the future.
+	ExtendBy time.Duration
+	// RedirectType sets the redirect status code; 0 reverts to the configured default.
+	RedirectType *int
+	// QueryPrecedence "" reverts to the configured default.
+	QueryPassthrough *bool
+	QueryPrecedence  *string
+	PathPassthrough  *bool
+	// UTMTemplate and UTM replace the link's UTM parameters; OriginalURL then sets the destination they
+	// are applied to. Setting both to empty values removes the parameters.
+	UTMTemplate *string
+	UTM         *domain.UTMParams
+	// TargetingRules replaces the link's targeting rules; an empty slice removes them.
+	TargetingRules *[]domain.TargetingRule
+	// Variants replaces the link's variants; an empty slice removes them.
+	Variants       *[]domain.Variant
+	StickyVariants *bool
+	// Password replaces the link's password; an empty string removes it.
+	Password *string
+	// MaxClicks replaces the link's click limit; 0 removes it. Clicks already made still count.
+	MaxClicks     *int64
+	AlwaysPreview *bool
+	// FallbackURL replaces the link's fallback URL; an empty string removes it.
+	FallbackURL *string
+	// Actor identifies who made the change in the URL's history.
+	Actor string
 }
 
-// GetOriginalURL retrieves the original URL by short code and increments access count.
-func (s *URLService) GetOriginalURL(ctx context.Context, shortCode string) (*domain.URL, error) {
+// UpdateURL applies update to the URL identified by shortCode. A non-zero version makes the update
+// conditional on the URL not having been modified since that version.
+func (s *URLService) UpdateURL(ctx context.Context, shortCode string, version int64, update URLUpdate) (*domain.URL, error) {
 	urlEntity, err := s.repo.GetByShortCode(ctx, shortCode)
 	if err != nil {
 		return nil, err
 	}
 
-	if urlEntity.IsExpired() {
-		s.logger.Warn("attempted to access expired url",
-			slog.String("short_code", shortCode),
-		)
-		return nil, domain.ErrURLExpired
+	if version != 0 && urlEntity.Version != version {
+		return nil, domain.ErrVersionMismatch
 	}
 
-	urlEntity.IncrementAccessCount()
+	if update.OriginalURL != nil || update.UTMTemplate != nil || update.UTM != nil {
+		baseURL := urlEntity.OriginalURL
+		var params domain.UTMParams
+		if urlEntity.UTM != nil {
+			baseURL, params = urlEntity.BaseURL, *urlEntity.UTM
+		}
 
-	if err := s.repo.Update(ctx, urlEntity); err != nil {
-		s.logger.Error("failed to update access count",
-			slog.String("error", err.Error()),
-			slog.String("short_code", shortCode),
-		)
+		if update.OriginalURL != nil {
+			if err := s.validateURL(*update.OriginalURL); err != nil {
+				return nil, fmt.Errorf("invalid url: %w", err)
+			}
+			baseURL = *update.OriginalURL
+		}
+
+		if update.UTMTemplate != nil || update.UTM != nil {
+			var templateName string
+			var inline domain.UTMParams
+			if update.UTMTemplate != nil {
+				templateName = *update.UTMTemplate
+			}
+			if update.UTM != nil {
+				inline = *update.UTM
+			}
+
+			if params, err = s.resolveUTM(ctx, templateName, inline); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := setDestination(urlEntity, baseURL, params); err != nil {
+			return nil, err
+		}
 	}
 
-	return urlEntity, nil
-}
+	now := time.Now()
 
-// GetURLMetadata retrieves URL metadata without incrementing access count.
-func (s *URLService) GetURLMetadata(ctx context.Context, shortCode string) (*domain.URL, error) {
-	urlEntity, err := s.repo.GetByShortCode(ctx, shortCode)
-	if err != nil {
+	if update.SetExpiry && update.ExtendBy != 0 {
+		return nil, fmt.Errorf("expiry cannot be set and extended at once: %w", domain.ErrInvalidExpiry)
+	}
+
+	if update.SetExpiry {
+		if update.ExpiresAt != nil && !update.ExpiresAt.After(now) {
+			return nil, fmt.Errorf("expiry must be in the future: %w", domain.ErrInvalidExpiry)
+		}
+		urlEntity.ExpiresAt = update.ExpiresAt
+	}
+
+	if update.ExtendBy != 0 {
+		if update.ExtendBy < 0 {
+			return nil, fmt.Errorf("extension must be positive: %w", domain.ErrInvalidExpiry)
+		}
+		if urlEntity.ExpiresAt == nil {
+			return nil, fmt.Errorf("url has no expiry to extend: %w", domain.ErrInvalidExpiry)
+		}
+
+		expiry := *urlEntity.ExpiresAt
+		if expiry.Before(now) {
+			expiry = now
+		}
+		expiry = expiry.Add(update.ExtendBy)
+		urlEntity.ExpiresAt = &expiry
+	}
+
+	if update.SetActivation {
+		urlEntity.ActivatesAt = update.ActivatesAt
+	}
+
+	if update.SetActivation || update.SetExpiry || update.ExtendBy != 0 {
+		if err := validateActivation(urlEntity); err != nil {
+			return nil, err
+		}
+	}
+
+	if update.RedirectType != nil {
+		if *update.RedirectType != 0 && !domain.IsValidRedirectType(*update.RedirectType) {
+			return nil, domain.ErrInvalidRedirectType
+		}
+		urlEntity.RedirectType = *update.RedirectType
+	}
+
+	if update.QueryPrecedence != nil {
+		if *update.QueryPrecedence != "" && !domain.IsValidQueryPrecedence(*update.QueryPrecedence) {
+			return nil, domain.ErrInvalidQueryPrecedence
+		}
+		urlEntity.QueryPrecedence = *update.QueryPrecedence
+	}
+
+	if update.QueryPassthrough != nil {
+		urlEntity.QueryPassthrough = *update.QueryPassthrough
+	}
+
+	if update.PathPassthrough != nil {
+		urlEntity.PathPassthrough = *update.PathPassthrough
+	}
+
+	if update.TargetingRules != nil {
+		if urlEntity.TargetingRules, err = s.validateTargetingRules(*update.TargetingRules); err != nil {
+			return nil, err
+		}
+	}
+
+	if update.Variants != nil {
+		if urlEntity.Variants, err = s.validateVariants(*update.Variants); err != nil {
+			return nil, err
+		}
+	}
+
+	if update.StickyVariants != nil {
+		urlEntity.StickyVariants = *update.StickyVariants
+	}
+
+	if update.AlwaysPreview != nil {
+		urlEntity.AlwaysPreview = *update.AlwaysPreview
+	}
+
+	if update.FallbackURL != nil {
+		if *update.FallbackURL != "" {
+			if err := s.validateURL(*update.FallbackURL); err != nil {
+				return nil, fmt.Errorf("invalid fallback url: %w", err)
+			}
+		}
+		urlEntity.FallbackURL = *update.FallbackURL
+	}
+
+	if update.MaxClicks != nil {
+		switch {
+		case *update.MaxClicks < 0:
+			return nil, domain.ErrInvalidClickLimit
+		case *update.MaxClicks == 0:
+			urlEntity.MaxClicks = nil
+		default:
+			urlEntity.MaxClicks = update.MaxClicks
+		}
+	}
+
+	if update.Password != nil {
+		urlEntity.PasswordHash = ""
+		if *update.Password != "" {
+			if urlEntity.PasswordHash, err = hashPassword(*update.Password); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	change := domain.Change{Action: domain.RevisionUpdate, Actor: update.Actor}
+	if err := s.saveAttributes(ctx, urlEntity, change); err != nil {
 		return nil, err
 	}
 
 	return urlEntity, nil
 }
 
-// DeleteURL deletes a URL by its short code.
-func (s *URLService) DeleteURL(ctx context.Context, shortCode string) error {
-	if err := s.repo.Delete(ctx, shortCode); err != nil {
-		return fmt.Errorf("failed to delete url: %w", err)
+// saveAttributes persists the editable attributes of urlEntity and records change in its history.
+func (s *URLService) saveAttributes(ctx context.Context, urlEntity *domain.URL, change domain.Change) error {
+	if err := s.repo.UpdateAttributes(ctx, urlEntity, change); err != nil {
+		if errors.Is(err, domain.ErrURLNotFound) || errors.Is(err, domain.ErrVersionMismatch) {
+			return err
+		}
+		return fmt.Errorf("failed to update url: %w", err)
 	}
 
-	s.logger.Info("url deleted", slog.String("short_code", shortCode))
+	s.logger.Info("url updated",
+		slog.String("short_code", urlEntity.ShortCode),
+		slog.String("action", change.Action),
+		slog.String("actor", change.Actor),
+		slog.String("original_url", urlEntity.OriginalURL),
+		slog.Any("expires_at", urlEntity.ExpiresAt),
+		slog.Int64("version", urlEntity.Version),
+	)
 
 	return nil
 }
 
+// GetURLMetadata retrieves URL metadata without incrementing access count.
+func (s *URLService) GetURLMetadata(ctx context.Context, shortCode string) (*domain.URL, error) {
+	urlEntity, err := s.repo.GetByShortCode(ctx, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return urlEntity, nil
+}
+
 // ListURLs retrieves a paginated list of URLs.
 func (s *URLService) ListURLs(ctx context.Context, limit, offset int) ([]*domain.URL, int64, error) {
 	if limit <= 0 || limit > 100 {
@@ -170,6 +472,22 @@ func (s *URLService) GetFullURL(shortCode string) string {
 	return fmt.Sprintf("%s/%s", baseURL, shortCode)
 }
 
+// NotLiveStatus returns the status code of responses to links visited before their activation time.
+func (s *URLService) NotLiveStatus() int {
+	return s.config.NotLiveStatus
+}
+
+// validateActivation checks that a link with an activation time becomes live before it expires.
+func validateActivation(urlEntity *domain.URL) error {
+	if urlEntity.ActivatesAt == nil || urlEntity.ExpiresAt == nil {
+		return nil
+	}
+	if !urlEntity.ActivatesAt.Before(*urlEntity.ExpiresAt) {
+		return fmt.Errorf("activation must be before expiry: %w", domain.ErrInvalidActivation)
+	}
+	return nil
+}
+
 func (s *URLService) validateURL(rawURL string) error {
 	if rawURL == "" {
 		return domain.ErrInvalidURL
@@ -191,6 +509,33 @@ func (s *URLService) validateURL(rawURL string) error {
 	return nil
 }
 
+// normalizeURL returns a canonical form of rawURL for duplicate detection: the scheme and host are
+// lower-cased, default ports are dropped, an empty path becomes "/" and query parameters are sorted.
+func normalizeURL(rawURL string) (string, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", domain.ErrInvalidURL
+	}
+
+	parsedURL.Scheme = strings.ToLower(parsedURL.Scheme)
+	parsedURL.Host = strings.ToLower(parsedURL.Host)
+
+	if port := parsedURL.Port(); (parsedURL.Scheme == "http" && port == "80") || (parsedURL.Scheme == "https" && port == "443") {
+		parsedURL.Host = strings.TrimSuffix(parsedURL.Host, ":"+port)
+	}
+
+	if parsedURL.Path == "" {
+		parsedURL.Path = "/"
+		parsedURL.RawPath = ""
+	}
+
+	if parsedURL.RawQuery != "" {
+		parsedURL.RawQuery = parsedURL.Query().Encode()
+	}
+
+	return parsedURL.String(), nil
+}
+
 func (s *URLService) validateShortCode(code string) error {
 	if len(code) < 3 || len(code) > 20 {
 		return domain.ErrInvalidShortCode
@@ -208,59 +553,96 @@ func (s *URLService) validateShortCode(code string) error {
 	return nil
 }
 
-func (s *URLService) generateShortCode(ctx context.Context) (string, error) {
+// insert stores urlEntity, generating a short code unless one is already set.
+// Generated codes that collide with an existing one are retried with a fresh candidate.
+func (s *URLService) insert(ctx context.Context, urlEntity *domain.URL, change domain.Change) error {
 	const maxAttempts = 10
 
+	customCode := urlEntity.ShortCode
+
 	for attempt := 0; attempt < maxAttempts; attempt++ {
-		code, err := generateRandomCode(s.config.ShortCodeLength)
-		if err != nil {
-			return "", fmt.Errorf("failed to generate random code: %w", err)
+		urlEntity.ID = 0
+		if s.codes.UsesID() {
+			id, err := s.repo.NextID(ctx)
+			if err != nil {
+				return err
+			}
+			urlEntity.ID = id
 		}
 
-		_, err = s.repo.GetByShortCode(ctx, code)
-		if err == domain.ErrURLNotFound {
-			return code, nil
+		if customCode != "" {
+			return s.repo.Create(ctx, urlEntity, change)
 		}
+
+		code, err := s.codes.Generate(urlEntity.ID, attempt)
 		if err != nil {
-			return "", fmt.Errorf("failed to check code uniqueness: %w", err)
+			return fmt.Errorf("failed to generate short code: %w", err)
+		}
+		urlEntity.ShortCode = code
+
+		err = s.repo.Create(ctx, urlEntity, change)
+		collided := errors.Is(err, domain.ErrShortCodeAlreadyExists)
+		if err != nil && !collided {
+			return err
+		}
+
+		s.recordCollision(collided)
+		if !collided {
+			return nil
 		}
 
 		s.logger.Debug("short code collision, retrying",
-			slog.String("code", code),
+			slog.String("code", urlEntity.ShortCode),
 			slog.Int("attempt", attempt+1),
 		)
 	}
 
-	return "", fmt.Errorf("failed to generate unique short code after %d attempts", maxAttempts)
+	return fmt.Errorf("failed to generate unique short code after %d attempts", maxAttempts)
 }
 
-func generateRandomCode(length int) (string, error) {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-
-	numBytes := (length * 6) / 8
-	if (length*6)%8 != 0 {
-		numBytes++
+// recordCollision tracks the outcome of a generated code and grows the code length
+// once collisions become frequent at the current length.
+func (s *URLService) recordCollision(collided bool) {
+	rate := s.collisions.record(collided)
+	if rate < collisionGrowThreshold {
+		return
 	}
 
-	randomBytes := make([]byte, numBytes)
-	if _, err := rand.Read(randomBytes); err != nil {
-		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	grower, ok := s.codes.(LengthGrower)
+	if !ok || !grower.Grow() {
+		return
 	}
 
-	encoded := base64.RawURLEncoding.EncodeToString(randomBytes)
+	s.collisions.reset()
+	s.logger.Warn("short code collision rate high, increasing code length",
+		slog.Float64("collision_rate", rate),
+		slog.Int("length", s.codes.Length()),
+		slog.Float64("keyspace_size", s.codes.KeyspaceSize()),
+	)
+}
 
-	if len(encoded) < length {
-		return "", fmt.Errorf("generated code too short")
-	}
+func generateRandomCode(length int) (string, error) {
+	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	// Bytes at or above the largest multiple of len(charset) are rejected so every character is equally likely.
+	const limit = 256 - 256%len(charset)
 
-	code := encoded[:length]
+	result := make([]byte, 0, length)
+	randomBytes := make([]byte, length+length/2)
+
+	for len(result) < length {
+		if _, err := rand.Read(randomBytes); err != nil {
+			return "", fmt.Errorf("failed to generate random bytes: %w", err)
+		}
 
-	result := make([]byte, length)
-	for i := 0; i < length; i++ {
-		if code[i] == '-' || code[i] == '_' {
-			result[i] = charset[int(randomBytes[i])%len(charset)]
-		} else {
-			result[i] = code[i]
+		for _, b := range randomBytes {
+			if int(b) >= limit {
+				continue
+			}
+			result = append(result, charset[int(b)%len(charset)])
+			if len(result) == length {
+				break
+			}
 		}
 	}
 
@@ -0,0 +1,430 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/config"
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/edson-mazvila/url-shortener/internal/migration"
+	"github.com/edson-mazvila/url-shortener/internal/repository"
+	"github.com/edson-mazvila/url-shortener/internal/storage"
+)
+
+// testBackend groups the stores of one storage driver.
+type testBackend struct {
+	name        string
+	urls        repository.URLStore
+	templates   repository.UTMTemplateStore
+	apiKeys     repository.APIKeyStore
+	idempotency repository.IdempotencyStore
+}
+
+// testBackends returns fresh in-memory stores and fresh stores on a migrated SQLite :memory: database.
+func testBackends(t *testing.T) []testBackend {
+	t.Helper()
+
+	logger := slog.New(slog.DiscardHandler)
+	ctx := context.Background()
+
+	db, err := storage.NewSQLiteDB(ctx, &config.DatabaseConfig{
+		Driver:       config.DriverSQLite,
+		Path:         config.SQLiteMemoryPath,
+		MaxOpenConns: 1,
+		MaxIdleConns: 1,
+	}, logger)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	migrator, err := migration.NewMigrator(db.DB(), migration.SQLite, logger)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("migrate sqlite: %v", err)
+	}
+
+	return []testBackend{
+		{
+			name:        "memory",
+			urls:        repository.NewMemoryURLRepository(logger),
+			templates:   repository.NewMemoryUTMTemplateRepository(logger),
+			apiKeys:     repository.NewMemoryAPIKeyRepository(logger),
+			idempotency: repository.NewMemoryIdempotencyRepository(logger),
+		},
+		{
+			name:        "sqlite",
+			urls:        repository.NewSQLiteURLRepository(db.DB(), logger),
+			templates:   repository.NewSQLiteUTMTemplateRepository(db.DB(), logger),
+			apiKeys:     repository.NewSQLiteAPIKeyRepository(db.DB(), logger),
+			idempotency: repository.NewSQLiteIdempotencyRepository(db.DB(), logger),
+		},
+	}
+}
+
+// newTestURLService creates a URL service with the default configuration on top of backend.
+func newTestURLService(t *testing.T, backend testBackend) *URLService {
+	t.Helper()
+
+	logger := slog.New(slog.DiscardHandler)
+	cfg := &config.URLConfig{
+		ShortCodeLength:     7,
+		BaseURL:             "http://localhost:8080",
+		CodeStrategy:        config.CodeStrategyRandom,
+		DefaultRedirectType: 302,
+		RedirectCacheMaxAge: 24 * time.Hour,
+		QueryPrecedence:     domain.QueryPrecedenceDestination,
+		VariantCookieTTL:    30 * 24 * time.Hour,
+		PasswordMaxAttempts: 5,
+		PasswordLockout:     15 * time.Minute,
+		NotLiveStatus:       404,
+	}
+	analytics := &config.AnalyticsConfig{MaxPending: 1000, QueueSize: 100, BatchSize: 100}
+
+	codes, err := NewCodeGenerator(cfg)
+	if err != nil {
+		t.Fatalf("NewCodeGenerator: %v", err)
+	}
+
+	return NewURLService(
+		backend.urls,
+		backend.templates,
+		codes,
+		NewClickAggregator(backend.urls, analytics, logger),
+		NewClickRecorder(repository.NewMemoryClickRepository(logger), analytics, logger),
+		nil,
+		cfg,
+		logger,
+	)
+}
+
+func TestCreateShortURL(t *testing.T) {
+	activatesLate := time.Now().Add(2 * time.Hour)
+
+	tests := []struct {
+		name        string
+		url         string
+		opts        CreateURLOptions
+		wantErr     error
+		wantCreated bool
+		check       func(t *testing.T, url *domain.URL)
+	}{
+		{
+			name:        "generated code",
+			url:         "https://example.com/page",
+			wantCreated: true,
+			check: func(t *testing.T, url *domain.URL) {
+				if len(url.ShortCode) != 7 {
+					t.Errorf("short code %q has length %d, want 7", url.ShortCode, len(url.ShortCode))
+				}
+				if url.Version != 1 {
+					t.Errorf("version = %d, want 1", url.Version)
+				}
+			},
+		},
+		{
+			name:        "custom code",
+			url:         "https://example.com/page",
+			opts:        CreateURLOptions{CustomCode: "my-link_1"},
+			wantCreated: true,
+			check: func(t *testing.T, url *domain.URL) {
+				if url.ShortCode != "my-link_1" {
+					t.Errorf("short code = %q, want my-link_1", url.ShortCode)
+				}
+			},
+		},
+		{
+			name:    "taken custom code",
+			url:     "https://example.com/other",
+			opts:    CreateURLOptions{CustomCode: "taken-code"},
+			wantErr: domain.ErrShortCodeAlreadyExists,
+		},
+		{
+			name:        "ttl",
+			url:         "https://example.com/page",
+			opts:        CreateURLOptions{TTL: time.Hour},
+			wantCreated: true,
+			check: func(t *testing.T, url *domain.URL) {
+				if url.ExpiresAt == nil || time.Until(*url.ExpiresAt) <= 59*time.Minute {
+					t.Errorf("expires at %v, want about an hour from now", url.ExpiresAt)
+				}
+			},
+		},
+		{
+			name:        "inline utm",
+			url:         "https://example.com/page?ref=1",
+			opts:        CreateURLOptions{UTM: domain.UTMParams{Source: "news", Medium: "email"}},
+			wantCreated: true,
+			check: func(t *testing.T, url *domain.URL) {
+				if !strings.Contains(url.OriginalURL, "utm_source=news") || !strings.Contains(url.OriginalURL, "ref=1") {
+					t.Errorf("original url = %q, want the base url with utm_source", url.OriginalURL)
+				}
+				if url.BaseURL != "https://example.com/page?ref=1" {
+					t.Errorf("base url = %q, want the url without utm parameters", url.BaseURL)
+				}
+			},
+		},
+		{
+			name:        "deduplicated",
+			url:         "HTTPS://Example.com:443/existing",
+			opts:        CreateURLOptions{Dedupe: true},
+			wantCreated: false,
+			check: func(t *testing.T, url *domain.URL) {
+				if url.ShortCode != "existing" {
+					t.Errorf("short code = %q, want the existing link", url.ShortCode)
+				}
+			},
+		},
+		{
+			name:        "deduplication skipped for click-limited links",
+			url:         "https://example.com/existing",
+			opts:        CreateURLOptions{Dedupe: true, MaxClicks: 3},
+			wantCreated: true,
+			check: func(t *testing.T, url *domain.URL) {
+				if url.ShortCode == "existing" {
+					t.Error("a click-limited link was deduplicated")
+				}
+			},
+		},
+		{name: "empty url", url: "", wantErr: domain.ErrInvalidURL},
+		{name: "unsupported scheme", url: "ftp://example.com/file", wantErr: domain.ErrInvalidURL},
+		{name: "relative url", url: "/just/a/path", wantErr: domain.ErrInvalidURL},
+		{name: "short custom code", url: "https://example.com", opts: CreateURLOptions{CustomCode: "ab"}, wantErr: domain.ErrInvalidShortCode},
+		{name: "custom code with a slash", url: "https://example.com", opts: CreateURLOptions{CustomCode: "a/b/c"}, wantErr: domain.ErrInvalidShortCode},
+		{name: "redirect type", url: "https://example.com", opts: CreateURLOptions{RedirectType: 303}, wantErr: domain.ErrInvalidRedirectType},
+		{name: "query precedence", url: "https://example.com", opts: CreateURLOptions{QueryPrecedence: "both"}, wantErr: domain.ErrInvalidQueryPrecedence},
+		{name: "negative click limit", url: "https://example.com", opts: CreateURLOptions{MaxClicks: -1}, wantErr: domain.ErrInvalidClickLimit},
+		{name: "fallback url", url: "https://example.com", opts: CreateURLOptions{FallbackURL: "javascript:alert(1)"}, wantErr: domain.ErrInvalidURL},
+		{name: "long password", url: "https://example.com", opts: CreateURLOptions{Password: strings.Repeat("p", 73)}, wantErr: domain.ErrInvalidPassword},
+		{name: "unknown utm template", url: "https://example.com", opts: CreateURLOptions{UTMTemplate: "missing"}, wantErr: domain.ErrUTMTemplateNotFound},
+		{
+			name:    "activation after expiry",
+			url:     "https://example.com",
+			opts:    CreateURLOptions{TTL: time.Hour, ActivatesAt: &activatesLate},
+			wantErr: domain.ErrInvalidActivation,
+		},
+		{
+			name:    "targeting rule without conditions",
+			url:     "https://example.com",
+			opts:    CreateURLOptions{TargetingRules: []domain.TargetingRule{{URL: "https://example.com/mobile"}}},
+			wantErr: domain.ErrInvalidTargetingRule,
+		},
+		{
+			name:    "single variant",
+			url:     "https://example.com",
+			opts:    CreateURLOptions{Variants: []domain.Variant{{URL: "https://example.com/a"}}},
+			wantErr: domain.ErrInvalidVariant,
+		},
+	}
+
+	for _, backend := range testBackends(t) {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc := newTestURLService(t, backend)
+
+			for _, code := range []string{"taken-code", "existing"} {
+				if _, _, err := svc.CreateShortURL(ctx, "https://example.com/"+code, CreateURLOptions{CustomCode: code}); err != nil {
+					t.Fatalf("create %s: %v", code, err)
+				}
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					url, created, err := svc.CreateShortURL(ctx, tt.url, tt.opts)
+					if tt.wantErr != nil {
+						if !errors.Is(err, tt.wantErr) {
+							t.Fatalf("got err %v, want %v", err, tt.wantErr)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatalf("CreateShortURL: %v", err)
+					}
+					if created != tt.wantCreated {
+						t.Errorf("created = %v, want %v", created, tt.wantCreated)
+					}
+					if tt.check != nil {
+						tt.check(t, url)
+					}
+
+					stored, err := svc.GetURLMetadata(ctx, url.ShortCode)
+					if err != nil {
+						t.Fatalf("GetURLMetadata: %v", err)
+					}
+					if stored.OriginalURL != url.OriginalURL {
+						t.Errorf("stored original url = %q, want %q", stored.OriginalURL, url.OriginalURL)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestUpdateURL(t *testing.T) {
+	newURL := "https://example.com/updated"
+	badURL := "mailto:someone@example.com"
+	past := time.Now().Add(-time.Hour)
+	noLimit := int64(0)
+	permanent := 301
+
+	tests := []struct {
+		name    string
+		version int64
+		update  URLUpdate
+		wantErr error
+		check   func(t *testing.T, url *domain.URL)
+	}{
+		{
+			name:    "matching version",
+			version: 1,
+			update:  URLUpdate{OriginalURL: &newURL},
+			check: func(t *testing.T, url *domain.URL) {
+				if url.OriginalURL != newURL {
+					t.Errorf("original url = %q, want %q", url.OriginalURL, newURL)
+				}
+				if url.Version != 2 {
+					t.Errorf("version = %d, want 2", url.Version)
+				}
+			},
+		},
+		{
+			name:   "unconditional",
+			update: URLUpdate{RedirectType: &permanent},
+			check: func(t *testing.T, url *domain.URL) {
+				if url.RedirectType != 301 {
+					t.Errorf("redirect type = %d, want 301", url.RedirectType)
+				}
+			},
+		},
+		{
+			name:   "remove click limit",
+			update: URLUpdate{MaxClicks: &noLimit},
+			check: func(t *testing.T, url *domain.URL) {
+				if url.MaxClicks != nil {
+					t.Errorf("max clicks = %d, want none", *url.MaxClicks)
+				}
+			},
+		},
+		{
+			name:   "extend expiry",
+			update: URLUpdate{ExtendBy: time.Hour},
+			check: func(t *testing.T, url *domain.URL) {
+				if url.ExpiresAt == nil || time.Until(*url.ExpiresAt) <= 119*time.Minute {
+					t.Errorf("expires at %v, want about two hours from now", url.ExpiresAt)
+				}
+			},
+		},
+		{name: "stale version", version: 7, update: URLUpdate{OriginalURL: &newURL}, wantErr: domain.ErrVersionMismatch},
+		{name: "invalid url", update: URLUpdate{OriginalURL: &badURL}, wantErr: domain.ErrInvalidURL},
+		{name: "expiry in the past", update: URLUpdate{SetExpiry: true, ExpiresAt: &past}, wantErr: domain.ErrInvalidExpiry},
+		{name: "negative extension", update: URLUpdate{ExtendBy: -time.Hour}, wantErr: domain.ErrInvalidExpiry},
+		{name: "set and extend", update: URLUpdate{SetExpiry: true, ExtendBy: time.Hour}, wantErr: domain.ErrInvalidExpiry},
+		{
+			name:    "activation after expiry",
+			update:  URLUpdate{SetActivation: true, ActivatesAt: func() *time.Time { at := time.Now().Add(3 * time.Hour); return &at }()},
+			wantErr: domain.ErrInvalidActivation,
+		},
+	}
+
+	for _, backend := range testBackends(t) {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc := newTestURLService(t, backend)
+
+			for i, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					code := "update-" + string(rune('a'+i))
+					created, _, err := svc.CreateShortURL(ctx, "https://example.com/original", CreateURLOptions{
+						CustomCode: code,
+						TTL:        time.Hour,
+						MaxClicks:  10,
+					})
+					if err != nil {
+						t.Fatalf("CreateShortURL: %v", err)
+					}
+
+					tt.update.Actor = "tester"
+					url, err := svc.UpdateURL(ctx, code, tt.version, tt.update)
+					if tt.wantErr != nil {
+						if !errors.Is(err, tt.wantErr) {
+							t.Fatalf("got err %v, want %v", err, tt.wantErr)
+						}
+
+						stored, err := svc.GetURLMetadata(ctx, code)
+						if err != nil {
+							t.Fatalf("GetURLMetadata: %v", err)
+						}
+						if stored.Version != created.Version || stored.OriginalURL != created.OriginalURL {
+							t.Errorf("rejected update changed the url: %+v", stored)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatalf("UpdateURL: %v", err)
+					}
+					tt.check(t, url)
+
+					stored, err := svc.GetURLMetadata(ctx, code)
+					if err != nil {
+						t.Fatalf("GetURLMetadata: %v", err)
+					}
+					tt.check(t, stored)
+				})
+			}
+
+			t.Run("unknown code", func(t *testing.T) {
+				if _, err := svc.UpdateURL(ctx, "missing", 0, URLUpdate{OriginalURL: &newURL}); !errors.Is(err, domain.ErrURLNotFound) {
+					t.Errorf("got err %v, want ErrURLNotFound", err)
+				}
+			})
+		})
+	}
+}
+
+// TestUpdateURLLostUpdate covers two clients that read the same version before either writes: the
+// store must reject the second write even though the service saw a matching version.
+func TestUpdateURLLostUpdate(t *testing.T) {
+	for _, backend := range testBackends(t) {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc := newTestURLService(t, backend)
+
+			if _, _, err := svc.CreateShortURL(ctx, "https://example.com/original", CreateURLOptions{CustomCode: "contended"}); err != nil {
+				t.Fatalf("CreateShortURL: %v", err)
+			}
+
+			first, err := backend.urls.GetByShortCode(ctx, "contended")
+			if err != nil {
+				t.Fatalf("GetByShortCode: %v", err)
+			}
+			second, err := backend.urls.GetByShortCode(ctx, "contended")
+			if err != nil {
+				t.Fatalf("GetByShortCode: %v", err)
+			}
+
+			first.OriginalURL = "https://example.com/first"
+			if err := backend.urls.UpdateAttributes(ctx, first, domain.Change{Action: domain.RevisionUpdate}); err != nil {
+				t.Fatalf("first update: %v", err)
+			}
+
+			second.OriginalURL = "https://example.com/second"
+			if err := backend.urls.UpdateAttributes(ctx, second, domain.Change{Action: domain.RevisionUpdate}); !errors.Is(err, domain.ErrVersionMismatch) {
+				t.Fatalf("second update: got err %v, want ErrVersionMismatch", err)
+			}
+
+			stored, err := svc.GetURLMetadata(ctx, "contended")
+			if err != nil {
+				t.Fatalf("GetURLMetadata: %v", err)
+			}
+			if stored.OriginalURL != "https://example.com/first" || stored.Version != 2 {
+				t.Errorf("stored %q at version %d, want the first update at version 2", stored.OriginalURL, stored.Version)
+			}
+		})
+	}
+}
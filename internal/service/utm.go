@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+)
+
+// resolveUTM returns the parameters of the named template, if any, overridden by inline.
+func (s *URLService) resolveUTM(ctx context.Context, templateName string, inline domain.UTMParams) (domain.UTMParams, error) {
+	var params domain.UTMParams
+
+	if templateName != "" {
+		template, err := s.templates.GetUTMTemplate(ctx, templateName)
+		if err != nil {
+			return params, err
+		}
+		params = template.UTMParams
+	}
+
+	params = params.Merge(inline)
+	if err := validateUTMParams(params); err != nil {
+		return params, err
+	}
+
+	return params, nil
+}
+
+// setDestination points urlEntity at baseURL with params applied, and records both separately so the
+// parameters can be edited later. Links without parameters keep no base URL.
+func setDestination(urlEntity *domain.URL, baseURL string, params domain.UTMParams) error {
+	destination := baseURL
+	urlEntity.BaseURL = ""
+	urlEntity.UTM = nil
+
+	if !params.IsZero() {
+		var err error
+		if destination, err = applyUTM(baseURL, params); err != nil {
+			return err
+		}
+		urlEntity.BaseURL = baseURL
+		urlEntity.UTM = &params
+	}
+
+	normalizedURL, err := normalizeURL(destination)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+
+	urlEntity.OriginalURL = destination
+	urlEntity.NormalizedURL = normalizedURL
+
+	return nil
+}
+
+// applyUTM sets the utm_* query parameters of baseURL, replacing any it already has.
+func applyUTM(baseURL string, params domain.UTMParams) (string, error) {
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", domain.ErrInvalidURL)
+	}
+
+	query := parsedURL.Query()
+	for key, value := range params.QueryParams() {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	parsedURL.RawQuery = query.Encode()
+
+	return parsedURL.String(), nil
+}
+
+// splitUTM separates the utm_* query parameters from rawURL.
+func splitUTM(rawURL string) (string, domain.UTMParams) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL, domain.UTMParams{}
+	}
+
+	query := parsedURL.Query()
+	params := domain.UTMParamsFromQuery(query)
+	if params.IsZero() {
+		return rawURL, params
+	}
+
+	for key := range params.QueryParams() {
+		query.Del(key)
+	}
+	parsedURL.RawQuery = query.Encode()
+
+	return parsedURL.String(), params
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+	"github.com/edson-mazvila/url-shortener/internal/repository"
+)
+
+const (
+	// maxUTMTemplateNameLength matches the utm_templates.name column.
+	maxUTMTemplateNameLength = 64
+	// maxUTMValueLength matches the utm_* columns.
+	maxUTMValueLength = 255
+)
+
+// UTMTemplateService manages reusable UTM parameter sets.
+type UTMTemplateService struct {
+	store  repository.UTMTemplateStore
+	logger *slog.Logger
+}
+
+// NewUTMTemplateService creates a new UTM template service.
+func NewUTMTemplateService(store repository.UTMTemplateStore, logger *slog.Logger) *UTMTemplateService {
+	return &UTMTemplateService{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// CreateTemplate creates a named UTM template.
+func (s *UTMTemplateService) CreateTemplate(ctx context.Context, name string, params domain.UTMParams) (*domain.UTMTemplate, error) {
+	if err := validateUTMTemplateName(name); err != nil {
+		return nil, err
+	}
+
+	if params.IsZero() {
+		return nil, fmt.Errorf("template sets no parameters: %w", domain.ErrInvalidUTM)
+	}
+
+	if err := validateUTMParams(params); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &domain.UTMTemplate{
+		Name:      name,
+		UTMParams: params,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.store.CreateUTMTemplate(ctx, template); err != nil {
+		if errors.Is(err, domain.ErrUTMTemplateExists) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create utm template: %w", err)
+	}
+
+	s.logger.Info("utm template created", slog.String("name", name))
+
+	return template, nil
+}
+
+// GetTemplate retrieves a UTM template by name.
+func (s *UTMTemplateService) GetTemplate(ctx context.Context, name string) (*domain.UTMTemplate, error) {
+	return s.store.GetUTMTemplate(ctx, name)
+}
+
+// ListTemplates retrieves all UTM templates.
+func (s *UTMTemplateService) ListTemplates(ctx context.Context) ([]*domain.UTMTemplate, error) {
+	templates, err := s.store.ListUTMTemplates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list utm templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// UpdateTemplate replaces the parameters of a UTM template. Links created from it keep their parameters.
+func (s *UTMTemplateService) UpdateTemplate(ctx context.Context, name string, params domain.UTMParams) (*domain.UTMTemplate, error) {
+	if params.IsZero() {
+		return nil, fmt.Errorf("template sets no parameters: %w", domain.ErrInvalidUTM)
+	}
+
+	if err := validateUTMParams(params); err != nil {
+		return nil, err
+	}
+
+	template := &domain.UTMTemplate{
+		Name:      name,
+		UTMParams: params,
+		UpdatedAt: time.Now(),
+	}
+
+	if err := s.store.UpdateUTMTemplate(ctx, template); err != nil {
+		if errors.Is(err, domain.ErrUTMTemplateNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update utm template: %w", err)
+	}
+
+	s.logger.Info("utm template updated", slog.String("name", name))
+
+	return template, nil
+}
+
+// DeleteTemplate deletes a UTM template. Links created from it keep their parameters.
+func (s *UTMTemplateService) DeleteTemplate(ctx context.Context, name string) error {
+	if err := s.store.DeleteUTMTemplate(ctx, name); err != nil {
+		if errors.Is(err, domain.ErrUTMTemplateNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to delete utm template: %w", err)
+	}
+
+	s.logger.Info("utm template deleted", slog.String("name", name))
+
+	return nil
+}
+
+func validateUTMTemplateName(name string) error {
+	if name == "" || len(name) > maxUTMTemplateNameLength {
+		return fmt.Errorf("template name must be 1-%d characters: %w", maxUTMTemplateNameLength, domain.ErrInvalidUTM)
+	}
+
+	for _, char := range name {
+		if !((char >= 'a' && char <= 'z') ||
+			(char >= 'A' && char <= 'Z') ||
+			(char >= '0' && char <= '9') ||
+			char == '-' || char == '_') {
+			return fmt.Errorf("template name may only contain letters, digits, '-' and '_': %w", domain.ErrInvalidUTM)
+		}
+	}
+
+	return nil
+}
+
+func validateUTMParams(params domain.UTMParams) error {
+	for key, value := range params.QueryParams() {
+		if len(value) > maxUTMValueLength {
+			return fmt.Errorf("%s exceeds %d characters: %w", key, maxUTMValueLength, domain.ErrInvalidUTM)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/edson-mazvila/url-shortener/internal/domain"
+)
+
+const (
+	// maxVariants bounds the destinations a single link can split its traffic across.
+	maxVariants = 10
+	// maxVariantNameLength matches the clicks.variant column.
+	maxVariantNameLength = 64
+	// maxVariantWeight keeps the sum of weights far from overflowing.
+	maxVariantWeight = 1000
+)
+
+// validateVariants checks variants, names unnamed variants after their position, starting at
+// "variant-1", and gives variants without a weight a weight of 1.
+func (s *URLService) validateVariants(variants []domain.Variant) ([]domain.Variant, error) {
+	if len(variants) == 0 {
+		return nil, nil
+	}
+	if len(variants) < 2 || len(variants) > maxVariants {
+		return nil, fmt.Errorf("a link needs between 2 and %d variants: %w", maxVariants, domain.ErrInvalidVariant)
+	}
+
+	validated := make([]domain.Variant, len(variants))
+	names := make(map[string]bool, len(variants))
+
+	for i, variant := range variants {
+		if variant.Name == "" {
+			variant.Name = fmt.Sprintf("variant-%d", i+1)
+		}
+		if err := validateVariantName(variant.Name); err != nil {
+			return nil, err
+		}
+		if names[variant.Name] {
+			return nil, fmt.Errorf("duplicate variant name %q: %w", variant.Name, domain.ErrInvalidVariant)
+		}
+		names[variant.Name] = true
+
+		if variant.Weight == 0 {
+			variant.Weight = 1
+		}
+		if variant.Weight < 0 || variant.Weight > maxVariantWeight {
+			return nil, fmt.Errorf("variant %q weight must be between 1 and %d: %w", variant.Name, maxVariantWeight, domain.ErrInvalidVariant)
+		}
+
+		if err := s.validateURL(variant.URL); err != nil {
+			return nil, fmt.Errorf("variant %q has an invalid url: %w", variant.Name, domain.ErrInvalidVariant)
+		}
+
+		validated[i] = variant
+	}
+
+	return validated, nil
+}
+
+// validateVariantName restricts names to characters that can be stored in a cookie unquoted.
+func validateVariantName(name string) error {
+	if len(name) > maxVariantNameLength {
+		return fmt.Errorf("variant name %q exceeds %d characters: %w", name, maxVariantNameLength, domain.ErrInvalidVariant)
+	}
+
+	for _, char := range name {
+		if !((char >= 'a' && char <= 'z') ||
+			(char >= 'A' && char <= 'Z') ||
+			(char >= '0' && char <= '9') ||
+			char == '-' || char == '_') {
+			return fmt.Errorf("variant name %q may only contain letters, digits, '-' and '_': %w", name, domain.ErrInvalidVariant)
+		}
+	}
+
+	return nil
+}
+
+// pickVariant returns the variant named sticky if the link still has it, and otherwise draws one at
+// random in proportion to the weights. It returns nil when there are no variants.
+func pickVariant(variants []domain.Variant, sticky string) *domain.Variant {
+	if len(variants) == 0 {
+		return nil
+	}
+
+	if sticky != "" {
+		for i := range variants {
+			if variants[i].Name == sticky {
+				return &variants[i]
+			}
+		}
+	}
+
+	var total int
+	for _, variant := range variants {
+		total += variant.Weight
+	}
+
+	n := rand.IntN(total)
+	for i := range variants {
+		n -= variants[i].Weight
+		if n < 0 {
+			return &variants[i]
+		}
+	}
+
+	return &variants[len(variants)-1]
+}
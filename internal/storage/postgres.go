@@ -2,17 +2,21 @@ package storage
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log/slog"
 	"time"
 
 	"github.com/edson-mazvila/url-shortener/internal/config"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
 )
 
 // PostgresDB wraps the PostgreSQL connection pool.
 type PostgresDB struct {
-	pool   *pgxpool.Pool
+	pool *pgxpool.Pool
+	// sqlDB is a database/sql handle sharing pool, for code such as the migrator that needs one.
+	sqlDB  *sql.DB
 	logger *slog.Logger
 }
 
@@ -50,6 +54,7 @@ func NewPostgresDB(ctx context.Context, cfg *config.DatabaseConfig, logger *slog
 
 	return &PostgresDB{
 		pool:   pool,
+		sqlDB:  stdlib.OpenDBFromPool(pool),
 		logger: logger,
 	}, nil
 }
@@ -59,9 +64,17 @@ func (db *PostgresDB) Pool() *pgxpool.Pool {
 	return db.pool
 }
 
-// Close closes the database connection pool.
+// SQLDB returns a database/sql handle backed by the connection pool. It is closed by Close.
+func (db *PostgresDB) SQLDB() *sql.DB {
+	return db.sqlDB
+}
+
+// Close closes the database/sql handle and the connection pool.
 func (db *PostgresDB) Close() {
 	db.logger.Info("closing database connection")
+	if err := db.sqlDB.Close(); err != nil {
+		db.logger.Error("failed to close database handle", slog.String("error", err.Error()))
+	}
 	db.pool.Close()
 }
 
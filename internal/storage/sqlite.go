@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/edson-mazvila/url-shortener/internal/config"
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteDB wraps an embedded SQLite database.
+type SQLiteDB struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+// sqlitePathEscaper escapes the characters that would otherwise end the path of a file: URI early.
+var sqlitePathEscaper = strings.NewReplacer("%", "%25", "?", "%3F", "#", "%23")
+
+// NewSQLiteDB opens the SQLite database file.
+func NewSQLiteDB(ctx context.Context, cfg *config.DatabaseConfig, logger *slog.Logger) (*SQLiteDB, error) {
+	dsn := url.URL{
+		Scheme:   "file",
+		Opaque:   sqlitePathEscaper.Replace(cfg.Path),
+		RawQuery: "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)&_time_format=sqlite",
+	}
+
+	db, err := sql.Open("sqlite", dsn.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	if cfg.Path == config.SQLiteMemoryPath {
+		// An in-memory database lives only as long as its single connection, so it is never recycled.
+		db.SetMaxIdleConns(1)
+		db.SetConnMaxLifetime(0)
+		db.SetConnMaxIdleTime(0)
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(pingCtx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	logger.Info("database connection established",
+		slog.String("driver", config.DriverSQLite),
+		slog.String("path", cfg.Path),
+	)
+
+	return &SQLiteDB{
+		db:     db,
+		logger: logger,
+	}, nil
+}
+
+// DB returns the underlying database handle.
+func (s *SQLiteDB) DB() *sql.DB {
+	return s.db
+}
+
+// Close closes the database.
+func (s *SQLiteDB) Close() {
+	s.logger.Info("closing database connection")
+	if err := s.db.Close(); err != nil {
+		s.logger.Error("failed to close sqlite database", slog.String("error", err.Error()))
+	}
+}
+
+// HealthCheck performs a health check on the database.
+func (s *SQLiteDB) HealthCheck(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("database health check failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package useragent
+
+import "strings"
+
+// Device classes.
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+)
+
+// Operating systems.
+const (
+	OSiOS      = "ios"
+	OSAndroid  = "android"
+	OSWindows  = "windows"
+	OSMacOS    = "macos"
+	OSLinux    = "linux"
+	OSChromeOS = "chromeos"
+	OSOther    = "other"
+)
+
+// Browsers.
+const (
+	BrowserChrome  = "chrome"
+	BrowserSafari  = "safari"
+	BrowserFirefox = "firefox"
+	BrowserEdge    = "edge"
+	BrowserOpera   = "opera"
+	BrowserSamsung = "samsung"
+	BrowserIE      = "ie"
+	BrowserOther   = "other"
+)
+
+// Info holds the attributes parsed from a User-Agent header.
+type Info struct {
+	Browser string `json:"browser"`
+	OS      string `json:"os"`
+	Device  string `json:"device"`
+}
+
+var botMarkers = []string{"bot", "crawler", "spider", "slurp", "curl/", "wget/", "python-requests", "go-http-client", "facebookexternalhit", "preview"}
+
+// Parse extracts the browser family, operating system and device class from a User-Agent string.
+// It recognises the common families only; anything else is reported as "other".
+func Parse(ua string) Info {
+	lower := strings.ToLower(ua)
+
+	return Info{
+		Browser: parseBrowser(lower),
+		OS:      parseOS(lower),
+		Device:  parseDevice(lower),
+	}
+}
+
+func parseBrowser(ua string) string {
+	switch {
+	case strings.Contains(ua, "edg/"), strings.Contains(ua, "edge/"), strings.Contains(ua, "edga/"), strings.Contains(ua, "edgios/"):
+		return BrowserEdge
+	case strings.Contains(ua, "opr/"), strings.Contains(ua, "opera"):
+		return BrowserOpera
+	case strings.Contains(ua, "samsungbrowser/"):
+		return BrowserSamsung
+	case strings.Contains(ua, "firefox/"), strings.Contains(ua, "fxios/"):
+		return BrowserFirefox
+	case strings.Contains(ua, "chrome/"), strings.Contains(ua, "crios/"), strings.Contains(ua, "chromium/"):
+		return BrowserChrome
+	case strings.Contains(ua, "safari/"):
+		return BrowserSafari
+	case strings.Contains(ua, "msie "), strings.Contains(ua, "trident/"):
+		return BrowserIE
+	default:
+		return BrowserOther
+	}
+}
+
+func parseOS(ua string) string {
+	switch {
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"), strings.Contains(ua, "ipod"):
+		return OSiOS
+	case strings.Contains(ua, "android"):
+		return OSAndroid
+	case strings.Contains(ua, "windows"):
+		return OSWindows
+	case strings.Contains(ua, "cros"):
+		return OSChromeOS
+	case strings.Contains(ua, "mac os x"), strings.Contains(ua, "macintosh"):
+		return OSMacOS
+	case strings.Contains(ua, "linux"):
+		return OSLinux
+	default:
+		return OSOther
+	}
+}
+
+func parseDevice(ua string) string {
+	for _, marker := range botMarkers {
+		if strings.Contains(ua, marker) {
+			return DeviceBot
+		}
+	}
+
+	switch {
+	case strings.Contains(ua, "ipad"), strings.Contains(ua, "tablet"),
+		strings.Contains(ua, "android") && !strings.Contains(ua, "mobile"):
+		return DeviceTablet
+	case strings.Contains(ua, "mobi"), strings.Contains(ua, "iphone"), strings.Contains(ua, "ipod"):
+		return DeviceMobile
+	default:
+		return DeviceDesktop
+	}
+}
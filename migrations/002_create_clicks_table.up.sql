@@ -0,0 +1,24 @@
+-- Create clicks table
+CREATE TABLE IF NOT EXISTS clicks (
+    id BIGSERIAL PRIMARY KEY,
+    url_id BIGINT NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    short_code VARCHAR(20) NOT NULL,
+    clicked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    referrer TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    request_id VARCHAR(128) NOT NULL DEFAULT ''
+);
+
+-- Create indexes for efficient queries
+CREATE INDEX IF NOT EXISTS idx_clicks_url_id_clicked_at ON clicks(url_id, clicked_at);
+
+-- Add comments for documentation
+COMMENT ON TABLE clicks IS 'Stores one row per successful redirect';
+COMMENT ON COLUMN clicks.url_id IS 'Shortened URL that was visited';
+COMMENT ON COLUMN clicks.short_code IS 'Short code at the time of the click';
+COMMENT ON COLUMN clicks.clicked_at IS 'Timestamp of the redirect';
+COMMENT ON COLUMN clicks.referrer IS 'Referer header sent by the client';
+COMMENT ON COLUMN clicks.user_agent IS 'User-Agent header sent by the client';
+COMMENT ON COLUMN clicks.ip_address IS 'Client IP address';
+COMMENT ON COLUMN clicks.request_id IS 'Request ID assigned by the server';
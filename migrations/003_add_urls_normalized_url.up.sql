@@ -0,0 +1,8 @@
+-- Add normalized destination for de-duplication
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS normalized_url TEXT;
+
+-- Create indexes for efficient queries
+CREATE INDEX IF NOT EXISTS idx_urls_normalized_url ON urls(normalized_url) WHERE normalized_url IS NOT NULL;
+
+-- Add comments for documentation
+COMMENT ON COLUMN urls.normalized_url IS 'Normalized original URL used to find duplicate destinations';
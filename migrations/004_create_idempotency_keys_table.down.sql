@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_idempotency_keys_expires_at;
+
+-- Drop table
+DROP TABLE IF EXISTS idempotency_keys;
@@ -0,0 +1,21 @@
+-- Create idempotency keys table
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key VARCHAR(255) PRIMARY KEY,
+    request_hash CHAR(64) NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    response_body BYTEA,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Create indexes for efficient queries
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+-- Add comments for documentation
+COMMENT ON TABLE idempotency_keys IS 'Stores responses of requests sent with an Idempotency-Key header';
+COMMENT ON COLUMN idempotency_keys.key IS 'Client supplied Idempotency-Key header value';
+COMMENT ON COLUMN idempotency_keys.request_hash IS 'SHA-256 of the request method, path and body';
+COMMENT ON COLUMN idempotency_keys.status_code IS 'Response status code, 0 while the request is in progress';
+COMMENT ON COLUMN idempotency_keys.response_body IS 'Response body replayed for repeated requests';
+COMMENT ON COLUMN idempotency_keys.created_at IS 'Timestamp when the key was first used';
+COMMENT ON COLUMN idempotency_keys.expires_at IS 'Timestamp after which the key may be reused';
@@ -0,0 +1,5 @@
+-- Add version for optimistic concurrency control
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+-- Add comments for documentation
+COMMENT ON COLUMN urls.version IS 'Incremented on every edit, exposed as the ETag';
@@ -0,0 +1,30 @@
+-- Create url history table
+CREATE TABLE IF NOT EXISTS url_history (
+    id BIGSERIAL PRIMARY KEY,
+    url_id BIGINT NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    version BIGINT NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    original_url TEXT NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    restored_version BIGINT,
+    changed_by VARCHAR(255) NOT NULL DEFAULT '',
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT url_history_url_id_version_key UNIQUE (url_id, version)
+);
+
+-- Record the current state of existing urls
+INSERT INTO url_history (url_id, version, action, original_url, expires_at, changed_at)
+SELECT id, version, CASE WHEN version = 1 THEN 'create' ELSE 'update' END, original_url, expires_at, created_at
+FROM urls
+ON CONFLICT DO NOTHING;
+
+-- Add comments for documentation
+COMMENT ON TABLE url_history IS 'Append-only log of changes to url destinations and expiries';
+COMMENT ON COLUMN url_history.url_id IS 'Shortened URL that was changed';
+COMMENT ON COLUMN url_history.version IS 'URL version produced by the change';
+COMMENT ON COLUMN url_history.action IS 'create, update or rollback';
+COMMENT ON COLUMN url_history.original_url IS 'Destination after the change';
+COMMENT ON COLUMN url_history.expires_at IS 'Expiry after the change';
+COMMENT ON COLUMN url_history.restored_version IS 'Version restored by a rollback';
+COMMENT ON COLUMN url_history.changed_by IS 'Actor that made the change';
+COMMENT ON COLUMN url_history.changed_at IS 'Timestamp of the change';
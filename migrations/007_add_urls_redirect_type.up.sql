@@ -0,0 +1,5 @@
+-- Add per-link redirect status code
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS redirect_type SMALLINT NOT NULL DEFAULT 0;
+
+-- Add comments for documentation
+COMMENT ON COLUMN urls.redirect_type IS 'HTTP status used to redirect (301, 302, 307 or 308), 0 for the configured default';
@@ -0,0 +1,4 @@
+-- Drop columns
+ALTER TABLE urls DROP COLUMN IF EXISTS path_passthrough;
+ALTER TABLE urls DROP COLUMN IF EXISTS query_precedence;
+ALTER TABLE urls DROP COLUMN IF EXISTS query_passthrough;
@@ -0,0 +1,9 @@
+-- Add query string and path passthrough options
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS query_passthrough BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS query_precedence VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS path_passthrough BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Add comments for documentation
+COMMENT ON COLUMN urls.query_passthrough IS 'Merge the query string of the visit into the destination';
+COMMENT ON COLUMN urls.query_precedence IS 'Which side wins on conflicting query parameters (destination or request), empty for the configured default';
+COMMENT ON COLUMN urls.path_passthrough IS 'Append path segments after the short code to the destination path';
@@ -0,0 +1,21 @@
+-- Create utm templates table
+CREATE TABLE IF NOT EXISTS utm_templates (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL UNIQUE,
+    utm_source VARCHAR(255) NOT NULL DEFAULT '',
+    utm_medium VARCHAR(255) NOT NULL DEFAULT '',
+    utm_campaign VARCHAR(255) NOT NULL DEFAULT '',
+    utm_term VARCHAR(255) NOT NULL DEFAULT '',
+    utm_content VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Add comments for documentation
+COMMENT ON TABLE utm_templates IS 'Reusable UTM parameter sets applied to campaign links';
+COMMENT ON COLUMN utm_templates.name IS 'Name links refer to the template by';
+COMMENT ON COLUMN utm_templates.utm_source IS 'utm_source value, empty when unset';
+COMMENT ON COLUMN utm_templates.utm_medium IS 'utm_medium value, empty when unset';
+COMMENT ON COLUMN utm_templates.utm_campaign IS 'utm_campaign value, empty when unset';
+COMMENT ON COLUMN utm_templates.utm_term IS 'utm_term value, empty when unset';
+COMMENT ON COLUMN utm_templates.utm_content IS 'utm_content value, empty when unset';
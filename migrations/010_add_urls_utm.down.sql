@@ -0,0 +1,7 @@
+-- Drop columns
+ALTER TABLE urls DROP COLUMN IF EXISTS utm_content;
+ALTER TABLE urls DROP COLUMN IF EXISTS utm_term;
+ALTER TABLE urls DROP COLUMN IF EXISTS utm_campaign;
+ALTER TABLE urls DROP COLUMN IF EXISTS utm_medium;
+ALTER TABLE urls DROP COLUMN IF EXISTS utm_source;
+ALTER TABLE urls DROP COLUMN IF EXISTS base_url;
@@ -0,0 +1,15 @@
+-- Store the base destination and UTM parameters of campaign links
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS base_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS utm_source VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS utm_medium VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS utm_campaign VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS utm_term VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS utm_content VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Add comments for documentation
+COMMENT ON COLUMN urls.base_url IS 'Destination before UTM parameters were applied, empty for links without UTM parameters';
+COMMENT ON COLUMN urls.utm_source IS 'utm_source applied to base_url';
+COMMENT ON COLUMN urls.utm_medium IS 'utm_medium applied to base_url';
+COMMENT ON COLUMN urls.utm_campaign IS 'utm_campaign applied to base_url';
+COMMENT ON COLUMN urls.utm_term IS 'utm_term applied to base_url';
+COMMENT ON COLUMN urls.utm_content IS 'utm_content applied to base_url';
@@ -0,0 +1,3 @@
+-- Drop columns
+ALTER TABLE clicks DROP COLUMN IF EXISTS target_rule;
+ALTER TABLE urls DROP COLUMN IF EXISTS targeting_rules;
@@ -0,0 +1,7 @@
+-- Add device and platform targeting rules
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS targeting_rules JSONB;
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS target_rule VARCHAR(64) NOT NULL DEFAULT '';
+
+-- Add comments for documentation
+COMMENT ON COLUMN urls.targeting_rules IS 'Ordered rules that send matching user agents to other destinations, NULL when the link has none';
+COMMENT ON COLUMN clicks.target_rule IS 'Name of the targeting rule that chose the destination, empty for the original url';
@@ -0,0 +1,2 @@
+-- Drop column
+ALTER TABLE clicks DROP COLUMN IF EXISTS country;
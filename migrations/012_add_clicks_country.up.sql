@@ -0,0 +1,5 @@
+-- Add the visitor country to click events
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS country VARCHAR(2) NOT NULL DEFAULT '';
+
+-- Add comments for documentation
+COMMENT ON COLUMN clicks.country IS 'ISO 3166-1 alpha-2 country resolved from ip_address, empty when unknown';
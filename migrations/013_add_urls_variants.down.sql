@@ -0,0 +1,4 @@
+-- Drop columns
+ALTER TABLE clicks DROP COLUMN IF EXISTS variant;
+ALTER TABLE urls DROP COLUMN IF EXISTS sticky_variants;
+ALTER TABLE urls DROP COLUMN IF EXISTS variants;
@@ -0,0 +1,9 @@
+-- Add weighted destination variants
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS variants JSONB;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS sticky_variants BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS variant VARCHAR(64) NOT NULL DEFAULT '';
+
+-- Add comments for documentation
+COMMENT ON COLUMN urls.variants IS 'Weighted destinations the link splits its traffic across, NULL when the link has none';
+COMMENT ON COLUMN urls.sticky_variants IS 'Whether returning visitors are sent to the variant they saw first';
+COMMENT ON COLUMN clicks.variant IS 'Name of the variant that chose the destination, empty when none did';
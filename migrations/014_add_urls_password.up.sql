@@ -0,0 +1,5 @@
+-- Add optional link passwords
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS password_hash VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Add comments for documentation
+COMMENT ON COLUMN urls.password_hash IS 'bcrypt hash of the password visitors must enter, empty for public links';
@@ -0,0 +1,3 @@
+-- Drop columns
+ALTER TABLE urls DROP COLUMN IF EXISTS used_clicks;
+ALTER TABLE urls DROP COLUMN IF EXISTS max_clicks;
@@ -0,0 +1,7 @@
+-- Add maximum click limits
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS max_clicks BIGINT;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS used_clicks BIGINT NOT NULL DEFAULT 0;
+
+-- Add comments for documentation
+COMMENT ON COLUMN urls.max_clicks IS 'Number of redirects after which the link stops working, NULL for unlimited';
+COMMENT ON COLUMN urls.used_clicks IS 'Redirects counted against max_clicks, incremented atomically on every redirect of a limited link';
@@ -0,0 +1,5 @@
+-- Add scheduled activation
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS activates_at TIMESTAMP WITH TIME ZONE;
+
+-- Add comments for documentation
+COMMENT ON COLUMN urls.activates_at IS 'Optional time before which the link does not redirect';
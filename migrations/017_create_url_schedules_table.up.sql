@@ -0,0 +1,20 @@
+-- Create url schedules table
+CREATE TABLE IF NOT EXISTS url_schedules (
+    id BIGSERIAL PRIMARY KEY,
+    url_id BIGINT NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    original_url TEXT NOT NULL,
+    apply_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_url_schedules_url_id ON url_schedules(url_id);
+CREATE INDEX IF NOT EXISTS idx_url_schedules_apply_at ON url_schedules(apply_at);
+
+-- Add comments for documentation
+COMMENT ON TABLE url_schedules IS 'Pending destination changes applied by the scheduler';
+COMMENT ON COLUMN url_schedules.url_id IS 'Shortened URL the change applies to';
+COMMENT ON COLUMN url_schedules.original_url IS 'Destination the URL switches to';
+COMMENT ON COLUMN url_schedules.apply_at IS 'Time from which the change is due';
+COMMENT ON COLUMN url_schedules.created_by IS 'Actor that scheduled the change';
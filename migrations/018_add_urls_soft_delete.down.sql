@@ -0,0 +1,8 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_urls_deleted_at;
+
+-- Drop columns
+ALTER TABLE urls DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE urls DROP COLUMN IF EXISTS disabled_at;
+
+COMMENT ON COLUMN url_history.action IS 'create, update or rollback';
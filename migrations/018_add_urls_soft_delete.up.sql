@@ -0,0 +1,11 @@
+-- Add disabled state and soft deletion
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_urls_deleted_at ON urls(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Add comments for documentation
+COMMENT ON COLUMN urls.disabled_at IS 'Time the link was disabled, NULL while it redirects';
+COMMENT ON COLUMN urls.deleted_at IS 'Time the link was soft-deleted; the row is purged once the quarantine has passed';
+COMMENT ON COLUMN url_history.action IS 'create, update, rollback, disable, enable, delete or restore';
@@ -0,0 +1,2 @@
+-- Drop columns
+ALTER TABLE urls DROP COLUMN IF EXISTS always_preview;
@@ -0,0 +1,5 @@
+-- Add preview mode
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS always_preview BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Add comments for documentation
+COMMENT ON COLUMN urls.always_preview IS 'Whether visitors see a preview page of the destination instead of being redirected';
@@ -0,0 +1,5 @@
+-- Add fallback destinations
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS fallback_url TEXT NOT NULL DEFAULT '';
+
+-- Add comments for documentation
+COMMENT ON COLUMN urls.fallback_url IS 'Destination used instead of an error once the link is unavailable, empty when none';
@@ -0,0 +1,22 @@
+-- Create api keys table
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    admin BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Add comments for documentation
+COMMENT ON TABLE api_keys IS 'Keys that grant access to the management API';
+COMMENT ON COLUMN api_keys.name IS 'Label identifying the key holder';
+COMMENT ON COLUMN api_keys.prefix IS 'Leading characters of the key, kept in the clear to tell keys apart';
+COMMENT ON COLUMN api_keys.key_hash IS 'Hex-encoded SHA-256 hash of the key';
+COMMENT ON COLUMN api_keys.admin IS 'Whether the key may manage API keys and use the admin endpoints';
+COMMENT ON COLUMN api_keys.created_by IS 'Actor that created the key';
+COMMENT ON COLUMN api_keys.last_used_at IS 'Time the key last authenticated a request, updated at most once a minute';
+COMMENT ON COLUMN api_keys.revoked_at IS 'Time the key was revoked, NULL while it is valid';
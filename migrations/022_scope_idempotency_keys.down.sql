@@ -0,0 +1,11 @@
+-- Keep one record per key before the key becomes globally unique again
+DELETE FROM idempotency_keys a
+USING idempotency_keys b
+WHERE a.key = b.key AND a.api_key_id > b.api_key_id;
+
+-- Drop column
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS api_key_id;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (key);
+
+COMMENT ON COLUMN idempotency_keys.key IS 'Client supplied Idempotency-Key header value';
@@ -0,0 +1,8 @@
+-- Scope idempotency keys to the API key that sent them
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS api_key_id BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (api_key_id, key);
+
+-- Add comments for documentation
+COMMENT ON COLUMN idempotency_keys.api_key_id IS 'API key that sent the request, 0 for the bootstrap key';
+COMMENT ON COLUMN idempotency_keys.key IS 'Client supplied Idempotency-Key header value, unique per API key';
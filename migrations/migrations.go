@@ -0,0 +1,27 @@
+// Package migrations embeds the SQL schema migrations into the server binary.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed *.sql
+var postgres embed.FS
+
+//go:embed sqlite/*.sql
+var sqlite embed.FS
+
+// Postgres returns the PostgreSQL migration scripts.
+func Postgres() fs.FS {
+	return postgres
+}
+
+// SQLite returns the SQLite migration scripts.
+func SQLite() fs.FS {
+	sub, err := fs.Sub(sqlite, "sqlite")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
@@ -0,0 +1,7 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_urls_access_count;
+DROP INDEX IF EXISTS idx_urls_expires_at;
+DROP INDEX IF EXISTS idx_urls_created_at;
+
+-- Drop table
+DROP TABLE IF EXISTS urls;
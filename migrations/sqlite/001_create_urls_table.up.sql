@@ -0,0 +1,16 @@
+-- Create urls table
+CREATE TABLE IF NOT EXISTS urls (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    short_code VARCHAR(20) NOT NULL UNIQUE,
+    original_url TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP,
+    access_count INTEGER NOT NULL DEFAULT 0,
+    last_accessed TIMESTAMP,
+    CONSTRAINT short_code_length_check CHECK (LENGTH(short_code) >= 3)
+);
+
+-- Create indexes for efficient queries
+CREATE INDEX IF NOT EXISTS idx_urls_created_at ON urls(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_urls_expires_at ON urls(expires_at) WHERE expires_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_urls_access_count ON urls(access_count DESC);
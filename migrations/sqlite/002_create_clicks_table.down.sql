@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_clicks_url_id_clicked_at;
+
+-- Drop table
+DROP TABLE IF EXISTS clicks;
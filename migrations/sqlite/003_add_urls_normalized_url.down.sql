@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_urls_normalized_url;
+
+-- Drop column
+ALTER TABLE urls DROP COLUMN normalized_url;
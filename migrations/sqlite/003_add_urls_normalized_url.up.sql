@@ -0,0 +1,5 @@
+-- Add normalized destination for de-duplication
+ALTER TABLE urls ADD COLUMN normalized_url TEXT;
+
+-- Create indexes for efficient queries
+CREATE INDEX IF NOT EXISTS idx_urls_normalized_url ON urls(normalized_url) WHERE normalized_url IS NOT NULL;
@@ -0,0 +1,2 @@
+-- Add version for optimistic concurrency control
+ALTER TABLE urls ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,18 @@
+-- Create url history table
+CREATE TABLE IF NOT EXISTS url_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url_id INTEGER NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    original_url TEXT NOT NULL,
+    expires_at TIMESTAMP,
+    restored_version INTEGER,
+    changed_by VARCHAR(255) NOT NULL DEFAULT '',
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT url_history_url_id_version_key UNIQUE (url_id, version)
+);
+
+-- Record the current state of existing urls
+INSERT OR IGNORE INTO url_history (url_id, version, action, original_url, expires_at, changed_at)
+SELECT id, version, CASE WHEN version = 1 THEN 'create' ELSE 'update' END, original_url, expires_at, created_at
+FROM urls;
@@ -0,0 +1,2 @@
+-- Add per-link redirect status code
+ALTER TABLE urls ADD COLUMN redirect_type INTEGER NOT NULL DEFAULT 0;
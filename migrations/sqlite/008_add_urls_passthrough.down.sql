@@ -0,0 +1,4 @@
+-- Drop columns
+ALTER TABLE urls DROP COLUMN path_passthrough;
+ALTER TABLE urls DROP COLUMN query_precedence;
+ALTER TABLE urls DROP COLUMN query_passthrough;
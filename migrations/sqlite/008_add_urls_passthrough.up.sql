@@ -0,0 +1,4 @@
+-- Add query string and path passthrough options
+ALTER TABLE urls ADD COLUMN query_passthrough BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE urls ADD COLUMN query_precedence VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN path_passthrough BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,7 @@
+-- Drop columns
+ALTER TABLE urls DROP COLUMN utm_content;
+ALTER TABLE urls DROP COLUMN utm_term;
+ALTER TABLE urls DROP COLUMN utm_campaign;
+ALTER TABLE urls DROP COLUMN utm_medium;
+ALTER TABLE urls DROP COLUMN utm_source;
+ALTER TABLE urls DROP COLUMN base_url;
@@ -0,0 +1,7 @@
+-- Store the base destination and UTM parameters of campaign links
+ALTER TABLE urls ADD COLUMN base_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN utm_source VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN utm_medium VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN utm_campaign VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN utm_term VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN utm_content VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- Drop columns
+ALTER TABLE clicks DROP COLUMN target_rule;
+ALTER TABLE urls DROP COLUMN targeting_rules;
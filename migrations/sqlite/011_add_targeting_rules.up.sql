@@ -0,0 +1,3 @@
+-- Add device and platform targeting rules
+ALTER TABLE urls ADD COLUMN targeting_rules TEXT;
+ALTER TABLE clicks ADD COLUMN target_rule VARCHAR(64) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- Add the visitor country to click events
+ALTER TABLE clicks ADD COLUMN country VARCHAR(2) NOT NULL DEFAULT '';
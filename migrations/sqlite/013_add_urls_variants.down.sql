@@ -0,0 +1,4 @@
+-- Drop columns
+ALTER TABLE clicks DROP COLUMN variant;
+ALTER TABLE urls DROP COLUMN sticky_variants;
+ALTER TABLE urls DROP COLUMN variants;
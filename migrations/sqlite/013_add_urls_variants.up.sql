@@ -0,0 +1,4 @@
+-- Add weighted destination variants
+ALTER TABLE urls ADD COLUMN variants TEXT;
+ALTER TABLE urls ADD COLUMN sticky_variants BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE clicks ADD COLUMN variant VARCHAR(64) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- Drop column
+ALTER TABLE urls DROP COLUMN password_hash;
@@ -0,0 +1,2 @@
+-- Add optional link passwords
+ALTER TABLE urls ADD COLUMN password_hash VARCHAR(255) NOT NULL DEFAULT '';
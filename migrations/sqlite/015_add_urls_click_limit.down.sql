@@ -0,0 +1,3 @@
+-- Drop columns
+ALTER TABLE urls DROP COLUMN used_clicks;
+ALTER TABLE urls DROP COLUMN max_clicks;
@@ -0,0 +1,3 @@
+-- Add maximum click limits
+ALTER TABLE urls ADD COLUMN max_clicks INTEGER;
+ALTER TABLE urls ADD COLUMN used_clicks INTEGER NOT NULL DEFAULT 0;
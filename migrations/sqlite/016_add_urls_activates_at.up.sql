@@ -0,0 +1,2 @@
+-- Add scheduled activation
+ALTER TABLE urls ADD COLUMN activates_at TIMESTAMP;
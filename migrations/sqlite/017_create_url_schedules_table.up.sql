@@ -0,0 +1,13 @@
+-- Create url schedules table
+CREATE TABLE IF NOT EXISTS url_schedules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url_id INTEGER NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    original_url TEXT NOT NULL,
+    apply_at TIMESTAMP NOT NULL,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_url_schedules_url_id ON url_schedules(url_id);
+CREATE INDEX IF NOT EXISTS idx_url_schedules_apply_at ON url_schedules(apply_at);
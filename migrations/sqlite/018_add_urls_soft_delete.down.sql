@@ -0,0 +1,6 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_urls_deleted_at;
+
+-- Drop columns
+ALTER TABLE urls DROP COLUMN deleted_at;
+ALTER TABLE urls DROP COLUMN disabled_at;
@@ -0,0 +1,6 @@
+-- Add disabled state and soft deletion
+ALTER TABLE urls ADD COLUMN disabled_at TIMESTAMP;
+ALTER TABLE urls ADD COLUMN deleted_at TIMESTAMP;
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_urls_deleted_at ON urls(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,2 @@
+-- Add preview mode
+ALTER TABLE urls ADD COLUMN always_preview BOOLEAN NOT NULL DEFAULT FALSE;
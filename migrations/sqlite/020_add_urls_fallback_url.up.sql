@@ -0,0 +1,2 @@
+-- Add fallback destinations
+ALTER TABLE urls ADD COLUMN fallback_url TEXT NOT NULL DEFAULT '';
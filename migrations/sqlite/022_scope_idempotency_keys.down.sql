@@ -0,0 +1,20 @@
+-- Restore the global key, keeping one record per key
+CREATE TABLE idempotency_keys_global (
+    key VARCHAR(255) PRIMARY KEY,
+    request_hash CHAR(64) NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    response_body BLOB,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+
+INSERT INTO idempotency_keys_global (key, request_hash, status_code, response_body, created_at, expires_at)
+SELECT key, request_hash, status_code, response_body, created_at, expires_at
+FROM idempotency_keys
+WHERE api_key_id = (SELECT MIN(api_key_id) FROM idempotency_keys i WHERE i.key = idempotency_keys.key);
+
+DROP TABLE idempotency_keys;
+ALTER TABLE idempotency_keys_global RENAME TO idempotency_keys;
+
+-- Create indexes for efficient queries
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,20 @@
+-- Scope idempotency keys to the API key that sent them; SQLite cannot change a primary key in place
+CREATE TABLE idempotency_keys_scoped (
+    api_key_id INTEGER NOT NULL DEFAULT 0,
+    key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    response_body BLOB,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (api_key_id, key)
+);
+
+INSERT INTO idempotency_keys_scoped (key, request_hash, status_code, response_body, created_at, expires_at)
+SELECT key, request_hash, status_code, response_body, created_at, expires_at FROM idempotency_keys;
+
+DROP TABLE idempotency_keys;
+ALTER TABLE idempotency_keys_scoped RENAME TO idempotency_keys;
+
+-- Create indexes for efficient queries
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
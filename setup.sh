@@ -41,12 +41,12 @@ echo ""
 echo "API Endpoint: http://localhost:8080"
 echo "Health Check: http://localhost:8080/health"
 echo ""
-echo "Example commands:"
+echo "Example commands (API_KEY is AUTH_BOOTSTRAP_KEY from .env, or a key created with it):"
 echo "  Create short URL:"
-echo "    curl -X POST http://localhost:8080/api/urls -H 'Content-Type: application/json' -d '{\"url\": \"https://github.com\"}'"
+echo "    curl -X POST http://localhost:8080/api/urls -H \"Authorization: Bearer \$API_KEY\" -H 'Content-Type: application/json' -d '{\"url\": \"https://github.com\"}'"
 echo ""
 echo "  List URLs:"
-echo "    curl http://localhost:8080/api/urls"
+echo "    curl -H \"Authorization: Bearer \$API_KEY\" http://localhost:8080/api/urls"
 echo ""
 echo "To stop: docker compose down"
 echo "To view logs: docker compose logs -f app"
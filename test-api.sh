@@ -7,6 +7,8 @@ RED='\033[0;31m'
 NC='\033[0m'
 
 BASE_URL="http://localhost:8080"
+# The management API needs a key, e.g. the server's AUTH_BOOTSTRAP_KEY.
+API_KEY="${API_KEY:?set API_KEY to an API key}"
 
 echo -e "${CYAN}URL Shortener API Test Suite${NC}"
 echo "========================================"
@@ -28,7 +30,7 @@ fi
 
 echo ""
 echo -e "${YELLOW}2. Creating Short URL (GitHub)${NC}"
-CREATE_RESPONSE=$(curl -s -w "\n%{http_code}" -X POST "$BASE_URL/api/urls" \
+CREATE_RESPONSE=$(curl -s -w "\n%{http_code}" -H "Authorization: Bearer $API_KEY" -X POST "$BASE_URL/api/urls" \
   -H "Content-Type: application/json" \
   -d '{"url": "https://github.com"}')
 HTTP_CODE=$(echo "$CREATE_RESPONSE" | tail -n1)
@@ -48,7 +50,7 @@ fi
 
 echo ""
 echo -e "${YELLOW}3. Creating Short URL with Custom Code${NC}"
-CUSTOM_RESPONSE=$(curl -s -w "\n%{http_code}" -X POST "$BASE_URL/api/urls" \
+CUSTOM_RESPONSE=$(curl -s -w "\n%{http_code}" -H "Authorization: Bearer $API_KEY" -X POST "$BASE_URL/api/urls" \
   -H "Content-Type: application/json" \
   -d '{"url": "https://golang.org", "custom_code": "golang"}')
 HTTP_CODE=$(echo "$CUSTOM_RESPONSE" | tail -n1)
@@ -64,7 +66,7 @@ fi
 
 echo ""
 echo -e "${YELLOW}4. Creating Short URL with Expiration (1 hour)${NC}"
-EXPIRE_RESPONSE=$(curl -s -w "\n%{http_code}" -X POST "$BASE_URL/api/urls" \
+EXPIRE_RESPONSE=$(curl -s -w "\n%{http_code}" -H "Authorization: Bearer $API_KEY" -X POST "$BASE_URL/api/urls" \
   -H "Content-Type: application/json" \
   -d '{"url": "https://example.com", "ttl": 3600}')
 HTTP_CODE=$(echo "$EXPIRE_RESPONSE" | tail -n1)
@@ -80,7 +82,7 @@ fi
 
 echo ""
 echo -e "${YELLOW}5. Getting URL Metadata${NC}"
-METADATA_RESPONSE=$(curl -s -w "\n%{http_code}" "$BASE_URL/api/urls/$SHORT_CODE")
+METADATA_RESPONSE=$(curl -s -w "\n%{http_code}" -H "Authorization: Bearer $API_KEY" "$BASE_URL/api/urls/$SHORT_CODE")
 HTTP_CODE=$(echo "$METADATA_RESPONSE" | tail -n1)
 BODY=$(echo "$METADATA_RESPONSE" | sed '$d')
 
@@ -105,7 +107,7 @@ fi
 
 echo ""
 echo -e "${YELLOW}7. Verifying Access Count Increment${NC}"
-METADATA_RESPONSE=$(curl -s -w "\n%{http_code}" "$BASE_URL/api/urls/$SHORT_CODE")
+METADATA_RESPONSE=$(curl -s -w "\n%{http_code}" -H "Authorization: Bearer $API_KEY" "$BASE_URL/api/urls/$SHORT_CODE")
 HTTP_CODE=$(echo "$METADATA_RESPONSE" | tail -n1)
 BODY=$(echo "$METADATA_RESPONSE" | sed '$d')
 
@@ -122,7 +124,7 @@ fi
 
 echo ""
 echo -e "${YELLOW}8. Listing URLs${NC}"
-LIST_RESPONSE=$(curl -s -w "\n%{http_code}" "$BASE_URL/api/urls?limit=5")
+LIST_RESPONSE=$(curl -s -w "\n%{http_code}" -H "Authorization: Bearer $API_KEY" "$BASE_URL/api/urls?limit=5")
 HTTP_CODE=$(echo "$LIST_RESPONSE" | tail -n1)
 BODY=$(echo "$LIST_RESPONSE" | sed '$d')
 
@@ -137,7 +139,7 @@ fi
 
 echo ""
 echo -e "${YELLOW}9. Testing Invalid URL${NC}"
-INVALID_RESPONSE=$(curl -s -w "\n%{http_code}" -X POST "$BASE_URL/api/urls" \
+INVALID_RESPONSE=$(curl -s -w "\n%{http_code}" -H "Authorization: Bearer $API_KEY" -X POST "$BASE_URL/api/urls" \
   -H "Content-Type: application/json" \
   -d '{"url": "not-a-valid-url"}')
 HTTP_CODE=$(echo "$INVALID_RESPONSE" | tail -n1)
@@ -150,7 +152,7 @@ fi
 
 echo ""
 echo -e "${YELLOW}10. Testing Duplicate Custom Code${NC}"
-DUPLICATE_RESPONSE=$(curl -s -w "\n%{http_code}" -X POST "$BASE_URL/api/urls" \
+DUPLICATE_RESPONSE=$(curl -s -w "\n%{http_code}" -H "Authorization: Bearer $API_KEY" -X POST "$BASE_URL/api/urls" \
   -H "Content-Type: application/json" \
   -d '{"url": "https://example.org", "custom_code": "golang"}')
 HTTP_CODE=$(echo "$DUPLICATE_RESPONSE" | tail -n1)
@@ -163,7 +165,7 @@ fi
 
 echo ""
 echo -e "${YELLOW}11. Testing Non-existent URL${NC}"
-NOTFOUND_RESPONSE=$(curl -s -w "\n%{http_code}" "$BASE_URL/api/urls/nonexistent123")
+NOTFOUND_RESPONSE=$(curl -s -w "\n%{http_code}" -H "Authorization: Bearer $API_KEY" "$BASE_URL/api/urls/nonexistent123")
 HTTP_CODE=$(echo "$NOTFOUND_RESPONSE" | tail -n1)
 
 if [ "$HTTP_CODE" -eq 404 ]; then
@@ -181,4 +183,4 @@ echo "  - $SHORT_URL → https://github.com"
 echo "  - $BASE_URL/golang → https://golang.org"
 echo ""
 echo "To delete a URL:"
-echo "  curl -X DELETE $BASE_URL/api/urls/$SHORT_CODE"
+echo "  curl -X DELETE -H \"Authorization: Bearer $API_KEY\" $BASE_URL/api/urls/$SHORT_CODE"